@@ -13,7 +13,8 @@ var configsetCmd = &cobra.Command{
     Long: `Examples:
 fargo config set hub.host 192.168.1.1
 fargo config set hub.port 2283
-fargo config set hub.ssl false`,
+fargo config set hub.ssl false
+fargo config set hub.endpoints "hub1.example.com:2283 hub2.example.com:2283"`,
     Run: config_set,
 }
 
@@ -42,7 +42,12 @@ func downloadRun(cmd *cobra.Command, args []string) {
 	if c, _ := cmd.Flags().GetInt("count"); c > 0 {
 		countFlag = uint32(c)
 	}
+	if allFlag, _ := cmd.Flags().GetBool("all"); allFlag {
+		countFlag = 0
+	}
 
+	maxDepthFlag, _ := cmd.Flags().GetInt("max-depth")
+	maxCastsFlag, _ := cmd.Flags().GetInt("max-casts")
 	dirFlag, _ := cmd.Flags().GetString("dir")
 	pretendFlag, _ := cmd.Flags().GetBool("pretend")
 	mimetypeFlag, _ := cmd.Flags().GetString("mime-type")
@@ -83,7 +88,7 @@ func downloadRun(cmd *cobra.Command, args []string) {
 		processURLs(urlList, download_dir, mimetypeFlag, pretendFlag, skipdownloadedFlag)
 	case len(parts) == 1 && strings.HasPrefix(parts[0], "0x"):
 		// TBA: grepFlag
-		casts := fctools.NewCastGroup().FromCastFidHash(hub, user.Fid, parts[0][2:], expandFlag)
+		casts := fctools.NewCastGroup().WithLimits(maxDepthFlag, maxCastsFlag).FromCastFidHash(hub, user.Fid, parts[0][2:], expandFlag)
 		urlList := []urls.Url{}
 		for _, u := range casts.Links() {
 			urlList = append(urlList, *urls.NewUrl(u).UpdateExt().UpdateType())
@@ -142,6 +147,9 @@ func init() {
 	rootCmd.AddCommand(downloadCmd)
 	downloadCmd.Flags().BoolP("recursive", "r", false, "Recursively get parent casts and replies")
 	downloadCmd.Flags().IntP("count", "c", 0, "Number of casts to show when getting @user/casts")
+	downloadCmd.Flags().BoolP("all", "", false, "Get all casts when getting @user/casts (overrides --count)")
+	downloadCmd.Flags().IntP("max-depth", "", 0, "Used with --recursive to limit how many levels of replies are fetched (0: no limit)")
+	downloadCmd.Flags().IntP("max-casts", "", 0, "Used with --recursive to limit how many casts of a thread are fetched (0: no limit)")
 	downloadCmd.Flags().StringP("grep", "", "", "Only show casts containing a specific string")
 	downloadCmd.Flags().StringP("mime-type", "", "", "Download embeds of mime/type")
 	downloadCmd.Flags().BoolP("pretend", "p", false, "Do not download the files, just print the URLs and local destination")
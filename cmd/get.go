@@ -10,13 +10,17 @@ fargo
 
 */
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/vrypan/fargo/config"
+	pb "github.com/vrypan/fargo/farcaster"
 	"github.com/vrypan/fargo/fctools"
 	db "github.com/vrypan/fargo/localdb"
 	"github.com/vrypan/fargo/tui"
@@ -29,34 +33,75 @@ var getCmd = &cobra.Command{
 	Long: `URI formats supported:
 - @username/casts
 - @username/reactions
+- @username/following
+- @username/mentions
+- @username/followers
+- @username/verifications
+- @username/account
+- @username/names
 - @username/0x<hash>
 - @username/0x<hash>/embed
+- @username/0x<hash>/likes
+- @username/0x<hash>/recasts
 - @username/0x<hash>/embed/<index>
-- @username/profile/[pfp|display|url|bio|username|location]`,
+- @username/profile/[pfp|display|url|bio|username|location]
+- channel:<url or alias>, or ~<alias>
+- addr:0x<address> (the fid whose custody address is <address>)
+
+Channel aliases are read from the "channels" config map, ex.:
+fargo config set channels.memes chain://eip155:1/erc721:0x...
+Unknown aliases are looked up as https://warpcast.com/~/channel/<alias>`,
 	Run: getRun,
 }
 
 func getRun(cmd *cobra.Command, args []string) {
 	// config.Load()
-	user, parts := parse_url(args)
-	if user == nil {
-		log.Fatal("User not found")
-	}
 	expandFlag, _ := cmd.Flags().GetBool("recursive")
 	jsonFlag, _ := cmd.Flags().GetBool("json")
 	jhexFlag, _ := cmd.Flags().GetBool("hex-hashes")
 	jdatesFlag, _ := cmd.Flags().GetBool("dates")
 	countFlag := uint32(config.GetInt("get.count"))
 	grepFlag, _ := cmd.Flags().GetString("grep")
+	maxDepthFlag, _ := cmd.Flags().GetInt("max-depth")
+	maxCastsFlag, _ := cmd.Flags().GetInt("max-casts")
+	sortString, _ := cmd.Flags().GetString("sort")
+	sortFlag, err := fctools.ParseSortMode(sortString)
+	if err != nil {
+		log.Fatal(err)
+	}
 	if c, _ := cmd.Flags().GetInt("count"); c > 0 {
 		countFlag = uint32(c)
 	}
+	if allFlag, _ := cmd.Flags().GetBool("all"); allFlag {
+		countFlag = 0
+	}
 
 	db.Open()
 	defer db.Close()
 	hub := fctools.NewFarcasterHub()
 	defer hub.Close()
 
+	if len(args) > 0 && IsChannelURI(args[0]) {
+		casts := fctools.NewCastGroup().FromParentUrl(hub, ChannelUrl(args[0]), countFlag).SortBy(hub, sortFlag)
+		if jsonFlag {
+			s, _ := casts.JsonList(jhexFlag, jdatesFlag)
+			fmt.Println(string(s))
+		} else {
+			fmt.Println(tui.PprintCastList(casts, nil, 0, grepFlag))
+		}
+		return
+	}
+
+	if len(args) > 0 && strings.HasPrefix(args[0], "addr:") {
+		getAddress(hub, strings.TrimPrefix(args[0], "addr:"), jsonFlag, jhexFlag, jdatesFlag)
+		return
+	}
+
+	user, parts := parse_url(args)
+	if user == nil {
+		log.Fatal("User not found")
+	}
+
 	switch {
 	case len(parts) == 1 && parts[0] == "profile":
 		user.FetchUserData(hub, nil)
@@ -84,7 +129,7 @@ func getRun(cmd *cobra.Command, args []string) {
 		}
 	case len(parts) == 1 && parts[0] == "casts":
 		// TBA: grepFlag
-		casts := fctools.NewCastGroup().FromFid(hub, user.Fid, countFlag)
+		casts := fctools.NewCastGroup().FromFid(hub, user.Fid, countFlag).SortBy(hub, sortFlag)
 		if jsonFlag {
 			s, _ := casts.JsonList(jhexFlag, jdatesFlag)
 			fmt.Println(string(s))
@@ -94,7 +139,7 @@ func getRun(cmd *cobra.Command, args []string) {
 		}
 	case len(parts) == 1 && parts[0] == "reactions":
 		reactions := fctools.NewReactions().FromFid(hub, user.Fid, "like", countFlag).CollectFnames(hub)
-		casts := fctools.NewCastGroup().FromCastIds(hub, reactions.CastIds()).CollectFnames(hub)
+		casts := fctools.NewCastGroup().FromCastIds(hub, reactions.CastIds()).CollectFnames(hub).SortBy(hub, sortFlag)
 		if jsonFlag {
 			s, _ := casts.JsonList(jhexFlag, jdatesFlag)
 			fmt.Println(string(s))
@@ -134,9 +179,98 @@ func getRun(cmd *cobra.Command, args []string) {
 			//s := tui.PprintCastList(casts, nil, 0, grepFlag)
 			//fmt.Println(s)
 		}
+	case len(parts) == 1 && parts[0] == "mentions":
+		sinceFlag, _ := cmd.Flags().GetBool("since")
+		dbKey := "MentionsSeen/" + strconv.FormatUint(user.Fid, 10)
+		var since uint32
+		seen := make(map[fctools.Hash]bool)
+		if sinceFlag {
+			if b, err := db.Get(dbKey); err == nil {
+				since, seen = parseMentionsSeen(string(b))
+			}
+		}
+		casts, err := fctools.NewCastGroup().FromMentions(hub, user.Fid, countFlag, since, seen)
+		if err != nil {
+			log.Printf("Error fetching mentions, the list is incomplete: %v", err)
+		}
+		casts.SortBy(hub, sortFlag)
+		if jsonFlag {
+			s, _ := casts.JsonList(jhexFlag, jdatesFlag)
+			fmt.Println(string(s))
+		} else {
+			fmt.Println(tui.PprintCastList(casts, nil, 0, grepFlag))
+		}
+		// Mentions hidden by --grep, or missing because of an error, are not seen yet.
+		if sinceFlag && err == nil && (grepFlag == "" || jsonFlag) && len(casts.Messages) > 0 {
+			newest, hashes := casts.Newest(), casts.NewestHashes()
+			if newest == since {
+				// New casts at the timestamp we had already seen.
+				for h := range seen {
+					hashes = append(hashes, h)
+				}
+			}
+			if err := db.SetPermanent(dbKey, []byte(fmtMentionsSeen(newest, hashes))); err != nil {
+				log.Printf("Could not save last seen mention: %v", err)
+			}
+		}
+	case len(parts) == 1 && (parts[0] == "following" || parts[0] == "followers"):
+		links := fctools.NewLinks()
+		if parts[0] == "following" {
+			links.FromFid(hub, user.Fid, fctools.LINK_TYPE_FOLLOW, countFlag)
+		} else {
+			links.FromTarget(hub, user.Fid, fctools.LINK_TYPE_FOLLOW, countFlag)
+		}
+		if jsonFlag {
+			s, _ := links.JsonList(jhexFlag, jdatesFlag)
+			fmt.Println(string(s))
+		} else {
+			fmt.Print(tui.PpLinksList(links.CollectFnames(hub), parts[0] == "followers"))
+		}
+	case len(parts) == 1 && parts[0] == "verifications":
+		verifications := fctools.NewVerifications().FromFid(hub, user.Fid, countFlag)
+		if jsonFlag {
+			s, _ := verifications.JsonList(jhexFlag, jdatesFlag)
+			fmt.Println(string(s))
+		} else {
+			fmt.Print(tui.PpVerificationsList(verifications))
+		}
+	case len(parts) == 1 && parts[0] == "account":
+		account, err := fctools.FetchAccount(hub, user.Fid)
+		if err != nil {
+			log.Fatal("Error fetching account: ", err)
+		}
+		if jsonFlag {
+			b, _ := json.MarshalIndent(account, "", "  ")
+			fmt.Println(string(b))
+		} else {
+			fnames := make(map[uint64]string)
+			for _, s := range account.Signers {
+				if s.AppFid != 0 {
+					fnames[s.AppFid], _ = hub.PrxGetUserDataStr(s.AppFid, "USER_DATA_TYPE_USERNAME")
+				}
+			}
+			fmt.Print(tui.PpAccount(account, fnames))
+		}
+	case len(parts) == 1 && parts[0] == "names":
+		proofs := fctools.NewUsernameProofs().FromFid(hub, user.Fid)
+		if verifyFlag, _ := cmd.Flags().GetBool("verify"); verifyFlag {
+			if err := proofs.Verify(hub); err != nil {
+				log.Fatal("Error verifying username proofs: ", err)
+			}
+		}
+		if jsonFlag {
+			b, _ := proofs.JsonList()
+			fmt.Println(string(b))
+		} else {
+			fmt.Print(tui.PpUsernameProofs(proofs))
+		}
 	case len(parts) == 1 && strings.HasPrefix(parts[0], "0x"):
 		// TBA: grepFlag
-		casts := fctools.NewCastGroup().FromCastFidHash(hub, user.Fid, parts[0][2:], expandFlag)
+		casts := fctools.NewCastGroup().WithLimits(maxDepthFlag, maxCastsFlag).FromCastFidHash(hub, user.Fid, parts[0][2:], expandFlag)
+		if statsFlag, _ := cmd.Flags().GetBool("stats"); statsFlag {
+			casts.CollectStats(hub)
+		}
+		casts.SortBy(hub, sortFlag)
 		if jsonFlag {
 			s, _ := casts.JsonThread(jhexFlag, jdatesFlag)
 			fmt.Println(string(s))
@@ -148,6 +282,26 @@ func getRun(cmd *cobra.Command, args []string) {
 			}
 
 		}
+	case len(parts) == 2 && strings.HasPrefix(parts[0], "0x") && (parts[1] == "likes" || parts[1] == "recasts"):
+		reactionType := "REACTION_TYPE_LIKE"
+		if parts[1] == "recasts" {
+			reactionType = "REACTION_TYPE_RECAST"
+		}
+		castId := &pb.CastId{Fid: user.Fid, Hash: HashToBytes(parts[0])}
+		reactions := fctools.NewReactions().FromTarget(hub, castId, reactionType, countFlag)
+		if jsonFlag {
+			s, _ := reactions.JsonList(jhexFlag, jdatesFlag)
+			fmt.Println(string(s))
+		} else {
+			total := len(reactions.Messages)
+			if countFlag > 0 && uint32(total) == countFlag {
+				// There may be more than we fetched.
+				if n, err := hub.CountReactionsByTarget(castId, reactionType); err == nil {
+					total = n
+				}
+			}
+			fmt.Print(tui.PpReactorsList(reactions.CollectFnames(hub), parts[1], total))
+		}
 	case len(parts) >= 2 && strings.HasPrefix(parts[0], "0x") && parts[1] == "embed":
 		casts := fctools.NewCastGroup().FromCastFidHash(hub, user.Fid, parts[0][2:], false)
 		embeds := casts.Messages[casts.Head].Message.Data.GetCastAddBody().GetEmbeds()
@@ -169,12 +323,86 @@ func getRun(cmd *cobra.Command, args []string) {
 	}
 }
 
+/*
+getAddress prints the fid whose custody address is address,
+and the IdRegistry event that assigned it.
+*/
+func getAddress(hub *fctools.FarcasterHub, address string, jsonFlag bool, jhexFlag bool, jdatesFlag bool) {
+	if !strings.HasPrefix(address, "0x") {
+		log.Fatal("Address should start with 0x")
+	}
+	addressBytes := HashToBytes(address)
+	if len(addressBytes) != 20 {
+		log.Fatal("Invalid Ethereum address")
+	}
+	event, err := hub.GetIdRegistryOnChainEventByAddress(addressBytes)
+	if errors.Is(err, fctools.ErrNotFound) {
+		log.Fatalf("%s is not the custody address of any fid", address)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+	if jsonFlag {
+		b, _ := fctools.JsonOnChainEvent(event, jhexFlag, jdatesFlag)
+		fmt.Println(string(b))
+		return
+	}
+	fname, _ := hub.PrxGetUserDataStr(event.Fid, "USER_DATA_TYPE_USERNAME")
+	fmt.Printf("%s (%d) custody: %s block: %d (%s)\n",
+		tui.PpFname(fname),
+		event.Fid,
+		fctools.EthChecksumAddress(addressBytes),
+		event.BlockNumber,
+		time.Unix(int64(event.BlockTimestamp), 0).Format("2006-01-02 15:04"),
+	)
+}
+
 func init() {
 	rootCmd.AddCommand(getCmd)
 	getCmd.Flags().BoolP("recursive", "r", false, "Recursively get parent casts and replies")
 	getCmd.Flags().IntP("count", "c", 0, "Number of casts to show when getting @user/casts")
+	getCmd.Flags().BoolP("all", "", false, "Get the full history when getting @user/casts, @user/reactions, @user/following, etc. (overrides --count)")
+	getCmd.Flags().IntP("max-depth", "", 0, "Used with --recursive to limit how many levels of replies are fetched (0: no limit)")
+	getCmd.Flags().IntP("max-casts", "", 0, "Used with --recursive to limit how many casts of a thread are fetched (0: no limit)")
+	getCmd.Flags().StringP("sort", "", "", "Sort casts and replies by time, time-desc, reactions or replies (default: the hub's order)")
 	getCmd.Flags().StringP("grep", "", "", "Only show casts containing a specific string")
 	getCmd.Flags().BoolP("json", "", false, "Generate a json object insteead of text")
 	getCmd.Flags().BoolP("hex-hashes", "", true, "Used with --json to show hashes in hex")
 	getCmd.Flags().BoolP("dates", "", false, "Used with --json to convert fc-timestamps to dates")
+	getCmd.Flags().BoolP("stats", "", false, "Show like, recast and reply counts of each cast in threads (also in --json)")
+	getCmd.Flags().BoolP("verify", "", false, "Used with @user/names to check that proofs are owned by the custody or a verified address")
+	getCmd.Flags().BoolP("since", "", false, "Used with @user/mentions to only show mentions newer than the last run (ignores --count, and with --grep does not mark mentions as seen)")
+}
+
+/*
+The MentionsSeen/<fid> localdb value is the Farcaster timestamp of the
+newest mention seen, followed by the hashes of the mentions seen at that
+timestamp: "<timestamp> 0x<hash>,0x<hash>".
+*/
+func parseMentionsSeen(s string) (uint32, map[fctools.Hash]bool) {
+	seen := make(map[fctools.Hash]bool)
+	fields := strings.Fields(s)
+	if len(fields) == 0 {
+		return 0, seen
+	}
+	timestamp, _ := strconv.ParseUint(fields[0], 10, 32)
+	if len(fields) > 1 {
+		for _, h := range strings.Split(fields[1], ",") {
+			if !strings.HasPrefix(h, "0x") {
+				continue
+			}
+			if b := HashToBytes(h); len(b) == len(fctools.Hash{}) {
+				seen[fctools.Hash(b)] = true
+			}
+		}
+	}
+	return uint32(timestamp), seen
+}
+
+func fmtMentionsSeen(timestamp uint32, hashes []fctools.Hash) string {
+	s := make([]string, len(hashes))
+	for i, h := range hashes {
+		s[i] = h.String()
+	}
+	return strconv.FormatUint(uint64(timestamp), 10) + " " + strings.Join(s, ",")
 }
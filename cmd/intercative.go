@@ -3,6 +3,7 @@ package cmd
 import (
 	"encoding/hex"
 	"fmt"
+	"io"
 	"log"
 	"os"
 	"strconv"
@@ -11,6 +12,8 @@ import (
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/spf13/cobra"
 	"github.com/vrypan/fargo/config"
+	pb "github.com/vrypan/fargo/farcaster"
+	"github.com/vrypan/fargo/fctools"
 	db "github.com/vrypan/fargo/localdb"
 	"github.com/vrypan/fargo/tui2"
 	history "github.com/vrypan/fargo/tui2/history2"
@@ -21,6 +24,11 @@ var interactiveCmd = &cobra.Command{
 	Short: "Interactive Farcaster explorer",
 	Long: `It only supports "@username/casts" for now.
 Ex.: fargo explore @dwr/casts
+
+Press "l" to like and "r" to recast the selected cast,
+using the cast.fid and cast.key (or cast.privkey/cast.pubkey)
+config values. The keystore passphrase is asked on the first
+like or recast.
 `,
 	Run: interactiveRun,
 }
@@ -38,7 +46,15 @@ func interactiveRun(cmd *cobra.Command, args []string) {
 		countFlag = uint32(c)
 	}
 	t := NewTuiModel2()
+	// The signer is loaded on the first like or recast.
+	t.cmd = cmd
 	t.casts.SetResultsCount(countFlag)
+	sortString, _ := cmd.Flags().GetString("sort")
+	sortFlag, err := fctools.ParseSortMode(sortString)
+	if err != nil {
+		log.Fatal(err)
+	}
+	t.casts.SetSortMode(sortFlag)
 
 	switch {
 	case len(parts) == 1 && parts[0] == "casts":
@@ -56,14 +72,46 @@ func interactiveRun(cmd *cobra.Command, args []string) {
 func init() {
 	rootCmd.AddCommand(interactiveCmd)
 	interactiveCmd.Flags().IntP("count", "c", 0, "Number of casts to show when getting @user/casts")
+	interactiveCmd.Flags().StringP("sort", "", "", "Sort casts and replies by time, time-desc, reactions or replies (default: the hub's order)")
+	interactiveCmd.Flags().StringP("key", "", "", "Name of a key in the keystore, used to like/recast. Overrides cast.key")
 }
 
 type tuiModel2 struct {
 	casts   *tui2.CastsModel
 	cursor  int
 	history *history.History
+
+	cmd          *cobra.Command
+	signerLoaded bool
+	fid          uint64
+	privateKey   []byte
+	publicKey    []byte
+	signerErr    error
+}
+
+// reactMsg asks for a reaction to be sent, once the signer is loaded.
+type reactMsg struct {
+	reactionType pb.ReactionType
+	castId       *pb.CastId
 }
 
+/*
+signerPrompt loads the signer while the TUI is suspended (see tea.Exec),
+so that the keystore passphrase can be asked on the terminal.
+*/
+type signerPrompt struct {
+	t *tuiModel2
+}
+
+func (p *signerPrompt) Run() error {
+	p.t.fid, p.t.privateKey, p.t.publicKey, p.t.signerErr = loadSigner(p.t.cmd)
+	p.t.signerLoaded = true
+	return p.t.signerErr
+}
+func (p *signerPrompt) SetStdin(io.Reader)  {}
+func (p *signerPrompt) SetStdout(io.Writer) {}
+func (p *signerPrompt) SetStderr(io.Writer) {}
+
 func NewTuiModel2() *tuiModel2 {
 	m := &tuiModel2{
 		casts:   tui2.NewCastsModel(),
@@ -170,9 +218,27 @@ func (t *tuiModel2) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				)
 				return t, tea.Sequence(cmds...)
 			}
+		case "l", "r":
+			cast := t.casts.GetCast(status.Cursor)
+			castId := &pb.CastId{Fid: cast.Message.Data.Fid, Hash: cast.Message.Hash}
+			reactionType := pb.ReactionType_REACTION_TYPE_LIKE
+			if msg.(tea.KeyMsg).String() == "r" {
+				reactionType = pb.ReactionType_REACTION_TYPE_RECAST
+			}
+			react := reactMsg{reactionType: reactionType, castId: castId}
+			if !t.signerLoaded {
+				return t, tea.Exec(&signerPrompt{t: t}, func(error) tea.Msg { return react })
+			}
+			return t, func() tea.Msg { return react }
 		default:
 			t.casts.Update(msg)
 		}
+	case reactMsg:
+		react := msg.(reactMsg)
+		return t, tea.Sequence(
+			func() tea.Msg { return tui2.UpdateStatusBar{Text: "Sending..."} },
+			func() tea.Msg { return tui2.UpdateStatusBar{Text: t.react(react.reactionType, react.castId)} },
+		)
 	case tea.WindowSizeMsg:
 		t.casts.Update(msg)
 	default:
@@ -181,6 +247,36 @@ func (t *tuiModel2) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return t, nil
 }
 
+// react submits a reaction to castId and returns the status bar text.
+func (t *tuiModel2) react(reactionType pb.ReactionType, castId *pb.CastId) string {
+	if t.signerErr != nil {
+		return t.signerErr.Error()
+	}
+	body := &pb.ReactionBody{
+		Type:   reactionType,
+		Target: &pb.ReactionBody_TargetCastId{TargetCastId: castId},
+	}
+	message := fctools.CreateMessage(
+		newReactionMessageData("MESSAGE_TYPE_REACTION_ADD", t.fid, body), t.privateKey, t.publicKey,
+	)
+	hub := fctools.NewFarcasterHub()
+	defer hub.Close()
+	if err := checkSigner(hub, t.fid, t.publicKey); err != nil {
+		return err.Error()
+	}
+	warning := ""
+	if usage, err := hub.GetStoreUsage(t.fid, pb.StoreType_STORE_TYPE_REACTIONS); err == nil && usage.Pruned(1) > 0 {
+		warning = " (reactions store full, oldest reaction pruned)"
+	}
+	if _, err := hub.SubmitMessage(message); err != nil {
+		return fmt.Sprintf("Error: %v", err)
+	}
+	if reactionType == pb.ReactionType_REACTION_TYPE_RECAST {
+		return "Recasted" + warning
+	}
+	return "Liked" + warning
+}
+
 func (t *tuiModel2) View() string {
 	return t.casts.View()
 }
@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"encoding/hex"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/vrypan/fargo/config"
+	"github.com/vrypan/fargo/fctools"
+	"github.com/vrypan/fargo/keystore"
+)
+
+var keysCheckCmd = &cobra.Command{
+	Use:   "check [name]",
+	Short: "Check if a key is an active signer of your fid",
+	Long: `Checks if the public key of [name] is registered on-chain
+as a signer of cast.fid (or --fid).
+
+If [name] is omitted, cast.key is used, or cast.pubkey
+if cast.key is not set. Use --pubkey to check any key.`,
+	Run: keysCheck,
+}
+
+func keysCheck(cmd *cobra.Command, args []string) {
+	fid := uint64(config.GetInt("cast.fid"))
+	if c, _ := cmd.Flags().GetUint64("fid"); c > 0 {
+		fid = c
+	}
+	if fid == 0 {
+		log.Fatal("No fid: fid is zero. Use --help to see options.")
+	}
+
+	keyName := config.GetString("cast.key")
+	if len(args) > 0 {
+		keyName = args[0]
+	}
+	s := config.GetString("cast.pubkey")
+	if keyName != "" {
+		key, err := keystore.Load(keyName)
+		if err != nil {
+			log.Fatalf("%s: %v", keyName, err)
+		}
+		s = key.PublicKey
+	}
+	if c, _ := cmd.Flags().GetString("pubkey"); c != "" {
+		s = c
+	}
+	publicKey, err := hex.DecodeString(strings.TrimPrefix(s, "0x"))
+	if err != nil || len(publicKey) == 0 {
+		log.Fatalf("Public key error: %v", err)
+	}
+
+	hub := fctools.NewFarcasterHub()
+	defer hub.Close()
+
+	event, err := hub.GetOnChainSigner(fid, publicKey)
+	if err == fctools.ERR_SIGNER_NOT_ACTIVE {
+		fmt.Printf("0x%x is NOT an active signer of fid %d\n", publicKey, fid)
+		if events, err := hub.GetOnChainSignersByFid(fid); err == nil {
+			fmt.Printf("Fid %d has %d active signers.\n", fid, len(events))
+		}
+		return
+	}
+	if err != nil {
+		log.Fatalf("Error checking signer: %v", err)
+	}
+	signer := fctools.Signer{Event: event}
+	fmt.Printf("%s is an active signer of fid %d\n", signer.KeyHex(), fid)
+	fmt.Printf("Key type: %s\n", signer.KeyType())
+	fmt.Printf("Added: %s (block %d)\n", signer.AddedAt().Format("2006-01-02 15:04"), event.BlockNumber)
+}
+
+func init() {
+	keysCmd.AddCommand(keysCheckCmd)
+	keysCheckCmd.Flags().Uint64P("fid", "", 0, "Fid to check. Overrides cast.fid")
+	keysCheckCmd.Flags().StringP("pubkey", "", "", "Public key to check. Ex: 0xdef1234....")
+}
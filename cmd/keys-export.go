@@ -0,0 +1,38 @@
+package cmd
+
+import (
+	"encoding/hex"
+	"fmt"
+	"log"
+
+	"github.com/spf13/cobra"
+	"github.com/vrypan/fargo/keystore"
+)
+
+var keysExportCmd = &cobra.Command{
+	Use:   "export [name]",
+	Short: "Print a decrypted keypair",
+	Long: `Prints the private and public key in the same format
+used by cast.privkey and cast.pubkey.`,
+	Run: keysExport,
+}
+
+func keysExport(cmd *cobra.Command, args []string) {
+	if len(args) != 1 {
+		log.Fatal("Wrong number of arguments: key name required")
+	}
+	key, err := keystore.Load(args[0])
+	if err != nil {
+		log.Fatalf("%s: %v", args[0], err)
+	}
+	privateKey, publicKey, err := key.Decrypt(readPassphrase("Passphrase: ", false))
+	if err != nil {
+		log.Fatalf("%s: %v", args[0], err)
+	}
+	fmt.Printf("privkey: 0x%s\n", hex.EncodeToString(privateKey))
+	fmt.Printf("pubkey: 0x%s\n", hex.EncodeToString(publicKey))
+}
+
+func init() {
+	keysCmd.AddCommand(keysExportCmd)
+}
@@ -0,0 +1,35 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/spf13/cobra"
+	"github.com/vrypan/fargo/keystore"
+)
+
+var keysGenerateCmd = &cobra.Command{
+	Use:   "generate [name]",
+	Short: "Generate a new signer keypair",
+	Long: `Generates a new Ed25519 keypair and stores it in the keystore.
+
+The public key must be registered as a signer of your fid
+before it can be used to post messages.`,
+	Run: keysGenerate,
+}
+
+func keysGenerate(cmd *cobra.Command, args []string) {
+	if len(args) != 1 {
+		log.Fatal("Wrong number of arguments: key name required")
+	}
+	passphrase := readPassphrase("Passphrase: ", true)
+	key, err := keystore.Generate(args[0], passphrase)
+	if err != nil {
+		log.Fatalf("Error generating key: %v", err)
+	}
+	fmt.Printf("%s: %s\n", key.Name, key.PublicKey)
+}
+
+func init() {
+	keysCmd.AddCommand(keysGenerateCmd)
+}
@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"encoding/hex"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/vrypan/fargo/config"
+	"github.com/vrypan/fargo/keystore"
+)
+
+var keysImportCmd = &cobra.Command{
+	Use:   "import [name] [0xprivkey]",
+	Short: "Import an existing private key",
+	Long: `Imports a hex-encoded private key into the keystore.
+
+If the private key is omitted, cast.privkey from the config
+file is imported. You can then remove it from config.yaml:
+
+fargo keys import default
+fargo config set cast.key default
+fargo config set cast.privkey ""`,
+	Run: keysImport,
+}
+
+func keysImport(cmd *cobra.Command, args []string) {
+	if len(args) < 1 || len(args) > 2 {
+		log.Fatal("Wrong number of arguments")
+	}
+	s := config.GetString("cast.privkey")
+	if len(args) == 2 {
+		s = args[1]
+	}
+	if s == "" {
+		log.Fatal("No private key given and cast.privkey is empty")
+	}
+	privateKey, err := hex.DecodeString(strings.TrimPrefix(s, "0x"))
+	if err != nil {
+		log.Fatalf("Private key error: %v", err)
+	}
+	passphrase := readPassphrase("Passphrase: ", true)
+	key, err := keystore.Import(args[0], privateKey, passphrase)
+	if err != nil {
+		log.Fatalf("Error importing key: %v", err)
+	}
+	fmt.Printf("%s: %s\n", key.Name, key.PublicKey)
+}
+
+func init() {
+	keysCmd.AddCommand(keysImportCmd)
+}
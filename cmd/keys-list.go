@@ -0,0 +1,35 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/spf13/cobra"
+	"github.com/vrypan/fargo/config"
+	"github.com/vrypan/fargo/keystore"
+)
+
+var keysListCmd = &cobra.Command{
+	Use:     "list",
+	Aliases: []string{"ls"},
+	Short:   "List the keys in the keystore",
+	Run:     keysList,
+}
+
+func keysList(cmd *cobra.Command, args []string) {
+	keys, err := keystore.List()
+	if err != nil {
+		log.Fatalf("Error reading keystore: %v", err)
+	}
+	for _, key := range keys {
+		def := ""
+		if key.Name == config.GetString("cast.key") {
+			def = " (default)"
+		}
+		fmt.Printf("%s: %s %s%s\n", key.Name, key.PublicKey, key.CreatedAt.Format("2006-01-02"), def)
+	}
+}
+
+func init() {
+	keysCmd.AddCommand(keysListCmd)
+}
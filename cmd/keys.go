@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/charmbracelet/x/term"
+	"github.com/spf13/cobra"
+	"github.com/vrypan/fargo/keystore"
+)
+
+var keysCmd = &cobra.Command{
+	Use:   "keys",
+	Short: "Manage signer keys",
+	Long: `Signer (app) keys are stored in an encrypted keystore
+in the fargo config directory, one file per key.
+
+The keystore passphrase is read from $FARGO_PASSPHRASE,
+or asked interactively.
+
+Once a key is in the keystore, "post" commands can use it
+with --key <name>, or you can set a default key with
+fargo config set cast.key <name>`,
+	Run: func(cmd *cobra.Command, args []string) {
+		cmd.Help()
+		fmt.Printf("\nKeystore path is %s\n", keystore.Path())
+	},
+}
+
+/*
+readPassphrase returns $FARGO_PASSPHRASE if set, otherwise asks
+for the passphrase. If confirm is true, the passphrase is asked twice.
+*/
+func readPassphrase(prompt string, confirm bool) string {
+	if p, ok := os.LookupEnv("FARGO_PASSPHRASE"); ok {
+		return p
+	}
+	if !term.IsTerminal(os.Stdin.Fd()) {
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && line == "" {
+			log.Fatalf("Error reading passphrase: %v", err)
+		}
+		return strings.TrimRight(line, "\r\n")
+	}
+	fmt.Fprint(os.Stderr, prompt)
+	p, err := term.ReadPassword(os.Stdin.Fd())
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		log.Fatalf("Error reading passphrase: %v", err)
+	}
+	if confirm {
+		fmt.Fprint(os.Stderr, "Repeat passphrase: ")
+		p2, err := term.ReadPassword(os.Stdin.Fd())
+		fmt.Fprintln(os.Stderr)
+		if err != nil {
+			log.Fatalf("Error reading passphrase: %v", err)
+		}
+		if string(p) != string(p2) {
+			log.Fatal("Passphrases do not match")
+		}
+	}
+	return string(p)
+}
+
+func init() {
+	rootCmd.AddCommand(keysCmd)
+}
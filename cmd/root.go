@@ -3,9 +3,11 @@ package cmd
 import (
 	"log"
 	"os"
+	"strings"
 
 	"github.com/spf13/cobra"
 	"github.com/vrypan/fargo/config"
+	"github.com/vrypan/fargo/fctools"
 )
 
 var rootCmd = &cobra.Command{
@@ -40,8 +42,14 @@ func Execute() {
 	}
 }
 
+func init() {
+	rootCmd.PersistentFlags().BoolP("verbose", "v", false, "Log which hub is used, and hub failovers")
+	config.BindPFlag("verbose", rootCmd.PersistentFlags().Lookup("verbose"))
+}
+
 func warnHoyt() {
-	if hub := config.GetString("hub.host"); hub == "hoyt.farcaster.xyz" && config.GetString("warn.hoyt") != "off" {
+	endpoints := fctools.HubEndpoints()
+	if len(endpoints) == 1 && strings.HasPrefix(endpoints[0], "hoyt.farcaster.xyz:") && config.GetString("warn.hoyt") != "off" {
 		log.Println("===========================================================")
 		log.Println(" WARNING: You are using the default hub: hoyt.farcaster.xyz")
 		log.Println(" For better performance, consider using a dedicated hub.")
@@ -2,13 +2,10 @@ package cmd
 
 import (
 	"encoding/hex"
-	"fmt"
 	"log"
 	"strconv"
-	"time"
 
 	"github.com/spf13/cobra"
-	"github.com/vrypan/fargo/config"
 	pb "github.com/vrypan/fargo/farcaster"
 	"github.com/vrypan/fargo/fctools"
 	db "github.com/vrypan/fargo/localdb"
@@ -31,43 +28,10 @@ func runSendCast(cmd *cobra.Command, args []string) {
 	db.Open()
 	defer db.Close()
 
-	var err error
-	var privateKey []byte
-	var publicKey []byte
-	var s string
+	fid, privateKey, publicKey := signerFromFlags(cmd)
 
 	var castMessageBodies []*pb.CastAddBody
 
-	s = config.GetString("cast.privkey")
-	if c, _ := cmd.Flags().GetString("privkey"); c != "" {
-		s = c
-	}
-	if len(s) < 2 {
-		log.Fatal("Private key error: private key string too short")
-	}
-	if privateKey, err = hex.DecodeString(s[2:]); err != nil {
-		log.Fatalf("Private key error: %v\nUse --help to see options.", err)
-	}
-
-	s = config.GetString("cast.pubkey")
-	if c, _ := cmd.Flags().GetString("pubkey"); c != "" {
-		s = c
-	}
-	if len(s) < 2 {
-		log.Fatal("Public key error: public key string too short")
-	}
-	if publicKey, err = hex.DecodeString(s[2:]); err != nil {
-		log.Fatalf("Public key error: %v\nUse --help to see options.", err)
-	}
-
-	fid := uint64(config.GetInt("cast.fid"))
-	if c, _ := cmd.Flags().GetUint64("fid"); c > 0 {
-		fid = c
-	}
-	if fid == 0 {
-		log.Fatal("No fid: fid is zero. Use --help to see options.")
-	}
-
 	replyToFlag, _ := cmd.Flags().GetString("reply-to")
 
 	prepareFlag, _ := cmd.Flags().GetBool("prepare")
@@ -111,6 +75,7 @@ func runSendCast(cmd *cobra.Command, args []string) {
 			break
 		}
 	}
+	checkStorage(cmd, hub, fid, "MESSAGE_TYPE_CAST_ADD", len(castMessageBodies))
 	for _, messageBody := range castMessageBodies {
 		if replyToFlag != "" {
 			parent, parentHashString := ParseFcURI(replyToFlag)
@@ -118,40 +83,18 @@ func runSendCast(cmd *cobra.Command, args []string) {
 			parentCast := &pb.CastAddBody_ParentCastId{ParentCastId: &pb.CastId{Fid: parent.Fid, Hash: parentHash}}
 			messageBody.Parent = parentCast
 		}
-		messageData := &pb.MessageData{
-			Type:      pb.MessageType(pb.MessageType_value["MESSAGE_TYPE_CAST_ADD"]),
-			Fid:       fid,
-			Timestamp: uint32(time.Now().Unix() - fctools.FARCASTER_EPOCH),
-			Network:   pb.FarcasterNetwork(pb.FarcasterNetwork_value["FARCASTER_NETWORK_MAINNET"]),
-			Body: &pb.MessageData_CastAddBody{
-				CastAddBody: messageBody,
-			},
+		messageData := newMessageData("MESSAGE_TYPE_CAST_ADD", fid)
+		messageData.Body = &pb.MessageData_CastAddBody{
+			CastAddBody: messageBody,
 		}
 		message := fctools.CreateMessage(messageData, privateKey, publicKey)
-		if prepareFlag {
-			jsonData, err := fctools.Marshal(
-				message, fctools.MarshalOptions{Bytes2Hash: true, Timestamp2Date: false},
-			)
-			if err != nil {
-				log.Fatal(err)
-			}
-			fmt.Println(string(jsonData))
-		} else {
-			msg, err := hub.SubmitMessage(message)
-			if err != nil {
-				log.Fatalf("Error submitting message: %v", err)
-			}
-			fmt.Printf("Sent: @%d/0x%s\n", msg.Data.Fid, hex.EncodeToString(msg.Hash))
-		}
+		submitOrPrint(hub, message, prepareFlag)
 		replyToFlag = "@" + strconv.FormatInt(int64(fid), 10) + "/" + "0x" + hex.EncodeToString(message.Hash)
 	}
 }
 
 func init() {
 	sendCmd.AddCommand(sendCastCmd)
-	sendCastCmd.Flags().Uint64P("fid", "", 0, "Fid who is casting")
-	sendCastCmd.Flags().StringP("pubkey", "", "", "Application public key. Ex: 0xdef1234....")
-	sendCastCmd.Flags().StringP("privkey", "", "", "Application private key. Ex: 0xabc1234....")
+	addSignerFlags(sendCastCmd)
 	sendCastCmd.Flags().StringP("reply-to", "", "", "Reply to a cast. The expected format is @fid/0xhash")
-	sendCastCmd.Flags().BoolP("prepare", "", false, "Prepare the Message object and print it, but don't send it")
 }
@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"log"
+
+	"github.com/spf13/cobra"
+	pb "github.com/vrypan/fargo/farcaster"
+	"github.com/vrypan/fargo/fctools"
+	db "github.com/vrypan/fargo/localdb"
+)
+
+var sendFollowCmd = &cobra.Command{
+	Use:   "follow @username",
+	Short: "Follow a user",
+	Run:   runSendFollow,
+}
+
+var sendUnfollowCmd = &cobra.Command{
+	Use:   "unfollow @username",
+	Short: "Unfollow a user",
+	Run:   runSendFollow,
+}
+
+func runSendFollow(cmd *cobra.Command, args []string) {
+	db.Open()
+	defer db.Close()
+
+	fid, privateKey, publicKey := signerFromFlags(cmd)
+	prepareFlag, _ := cmd.Flags().GetBool("prepare")
+
+	if len(args) == 0 {
+		log.Fatal("Missing arguments: @username required")
+	}
+	target, parts := ParseFcURI(args[0])
+	if target == nil || len(parts) > 0 {
+		log.Fatal("User not found. The expected format is @username")
+	}
+
+	messageType := "MESSAGE_TYPE_LINK_ADD"
+	if cmd.Name() == "unfollow" {
+		messageType = "MESSAGE_TYPE_LINK_REMOVE"
+	}
+
+	hub := fctools.NewFarcasterHub()
+	defer hub.Close()
+
+	messageData := newMessageData(messageType, fid)
+	messageData.Body = &pb.MessageData_LinkBody{
+		LinkBody: &pb.LinkBody{
+			Type:   fctools.LINK_TYPE_FOLLOW,
+			Target: &pb.LinkBody_TargetFid{TargetFid: target.Fid},
+		},
+	}
+	checkStorageOf(cmd, hub, []*pb.MessageData{messageData})
+	message := fctools.CreateMessage(messageData, privateKey, publicKey)
+	submitOrPrint(hub, message, prepareFlag)
+}
+
+func init() {
+	sendCmd.AddCommand(sendFollowCmd)
+	sendCmd.AddCommand(sendUnfollowCmd)
+	addSignerFlags(sendFollowCmd)
+	addSignerFlags(sendUnfollowCmd)
+}
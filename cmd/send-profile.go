@@ -0,0 +1,103 @@
+package cmd
+
+import (
+	"log"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	pb "github.com/vrypan/fargo/farcaster"
+	"github.com/vrypan/fargo/fctools"
+	db "github.com/vrypan/fargo/localdb"
+	"gopkg.in/yaml.v3"
+)
+
+var sendProfileCmd = &cobra.Command{
+	Use:   "profile",
+	Short: "Update your profile",
+	Run: func(cmd *cobra.Command, args []string) {
+		cmd.Help()
+	},
+}
+
+var sendProfileSetCmd = &cobra.Command{
+	Use:   "set [pfp|display|url|bio|username|location] [value]",
+	Short: "Set a profile field",
+	Long: `Field names are the same used by "get @username/profile/<field>".
+
+Examples:
+fargo post profile set bio "Hello world"
+fargo post profile set location geo:37.97,23.72
+fargo post profile set --from-file profile.yaml
+
+profile.yaml is a map of field names to values:
+
+display: "My Name"
+bio: "Hello world"`,
+	Run: runSendProfileSet,
+}
+
+func runSendProfileSet(cmd *cobra.Command, args []string) {
+	db.Open()
+	defer db.Close()
+
+	fid, privateKey, publicKey := signerFromFlags(cmd)
+	prepareFlag, _ := cmd.Flags().GetBool("prepare")
+	fromFileFlag, _ := cmd.Flags().GetString("from-file")
+
+	fields := make(map[string]string)
+	switch {
+	case fromFileFlag != "" && len(args) == 0:
+		b, err := os.ReadFile(fromFileFlag)
+		if err != nil {
+			log.Fatalf("Error reading %s: %v", fromFileFlag, err)
+		}
+		if err := yaml.Unmarshal(b, &fields); err != nil {
+			log.Fatalf("Error parsing %s: %v", fromFileFlag, err)
+		}
+	case fromFileFlag == "" && len(args) == 2:
+		fields[args[0]] = args[1]
+	default:
+		log.Fatal("Expected [field] [value] or --from-file")
+	}
+
+	// Validate everything before signing anything.
+	names := make([]string, 0, len(fields))
+	for name, value := range fields {
+		t := strings.ToUpper("USER_DATA_TYPE_" + name)
+		if err := fctools.ValidateUserData(t, value); err != nil {
+			log.Fatal(err)
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	hub := fctools.NewFarcasterHub()
+	defer hub.Close()
+
+	data := make([]*pb.MessageData, len(names))
+	for i, name := range names {
+		t := strings.ToUpper("USER_DATA_TYPE_" + name)
+		data[i] = newMessageData("MESSAGE_TYPE_USER_DATA_ADD", fid)
+		data[i].Body = &pb.MessageData_UserDataBody{
+			UserDataBody: &pb.UserDataBody{
+				Type:  pb.UserDataType(pb.UserDataType_value[t]),
+				Value: fields[name],
+			},
+		}
+	}
+	// Only the fields that are not set yet use more storage.
+	checkStorageOf(cmd, hub, data)
+	for _, messageData := range data {
+		message := fctools.CreateMessage(messageData, privateKey, publicKey)
+		submitOrPrint(hub, message, prepareFlag)
+	}
+}
+
+func init() {
+	sendCmd.AddCommand(sendProfileCmd)
+	sendProfileCmd.AddCommand(sendProfileSetCmd)
+	addSignerFlags(sendProfileSetCmd)
+	sendProfileSetCmd.Flags().StringP("from-file", "", "", "YAML file with the fields to set")
+}
@@ -0,0 +1,114 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/spf13/cobra"
+	pb "github.com/vrypan/fargo/farcaster"
+	"github.com/vrypan/fargo/fctools"
+	db "github.com/vrypan/fargo/localdb"
+)
+
+const reactionLong = `The target can be a cast (@username/0x<hash>)
+or a URL (ex. https://example.com).`
+
+var sendLikeCmd = &cobra.Command{
+	Use:   "like [@username/0x<hash>|url]",
+	Short: "Like a cast or URL",
+	Long:  reactionLong,
+	Run:   runSendReaction,
+}
+
+var sendUnlikeCmd = &cobra.Command{
+	Use:   "unlike [@username/0x<hash>|url]",
+	Short: "Remove a like",
+	Long:  reactionLong,
+	Run:   runSendReaction,
+}
+
+var sendRecastCmd = &cobra.Command{
+	Use:   "recast [@username/0x<hash>|url]",
+	Short: "Recast a cast or URL",
+	Long:  reactionLong,
+	Run:   runSendReaction,
+}
+
+var sendUnrecastCmd = &cobra.Command{
+	Use:   "unrecast [@username/0x<hash>|url]",
+	Short: "Remove a recast",
+	Long:  reactionLong,
+	Run:   runSendReaction,
+}
+
+func runSendReaction(cmd *cobra.Command, args []string) {
+	db.Open()
+	defer db.Close()
+
+	fid, privateKey, publicKey := signerFromFlags(cmd)
+	prepareFlag, _ := cmd.Flags().GetBool("prepare")
+
+	if len(args) == 0 {
+		log.Fatal("Missing arguments: target required")
+	}
+	body, err := parseReactionTarget(args[0])
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	messageType := "MESSAGE_TYPE_REACTION_ADD"
+	if strings.HasPrefix(cmd.Name(), "un") {
+		messageType = "MESSAGE_TYPE_REACTION_REMOVE"
+	}
+	body.Type = pb.ReactionType_REACTION_TYPE_LIKE
+	if strings.HasSuffix(cmd.Name(), "recast") {
+		body.Type = pb.ReactionType_REACTION_TYPE_RECAST
+	}
+
+	hub := fctools.NewFarcasterHub()
+	defer hub.Close()
+
+	messageData := newReactionMessageData(messageType, fid, body)
+	checkStorageOf(cmd, hub, []*pb.MessageData{messageData})
+	message := fctools.CreateMessage(messageData, privateKey, publicKey)
+	submitOrPrint(hub, message, prepareFlag)
+}
+
+/*
+parseReactionTarget returns a ReactionBody with its target set.
+Targets starting with @ are expected to be @username/0x<hash> casts,
+anything else is treated as a URL.
+*/
+func parseReactionTarget(target string) (*pb.ReactionBody, error) {
+	if !strings.HasPrefix(target, "@") {
+		return &pb.ReactionBody{Target: &pb.ReactionBody_TargetUrl{TargetUrl: target}}, nil
+	}
+	user, parts := ParseFcURI(target)
+	if user == nil {
+		return nil, fmt.Errorf("User not found")
+	}
+	if len(parts) != 1 || !strings.HasPrefix(parts[0], "0x") {
+		return nil, fmt.Errorf("Expected @username/0x<hash>")
+	}
+	hash := HashToBytes(parts[0])
+	if hash == nil {
+		return nil, fmt.Errorf("Invalid hash: %s", parts[0])
+	}
+	return &pb.ReactionBody{
+		Target: &pb.ReactionBody_TargetCastId{TargetCastId: &pb.CastId{Fid: user.Fid, Hash: hash}},
+	}, nil
+}
+
+func newReactionMessageData(messageType string, fid uint64, body *pb.ReactionBody) *pb.MessageData {
+	messageData := newMessageData(messageType, fid)
+	messageData.Body = &pb.MessageData_ReactionBody{ReactionBody: body}
+	return messageData
+}
+
+func init() {
+	for _, c := range []*cobra.Command{sendLikeCmd, sendUnlikeCmd, sendRecastCmd, sendUnrecastCmd} {
+		sendCmd.AddCommand(c)
+		addSignerFlags(c)
+	}
+}
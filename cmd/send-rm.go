@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"encoding/hex"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	pb "github.com/vrypan/fargo/farcaster"
+	"github.com/vrypan/fargo/fctools"
+	db "github.com/vrypan/fargo/localdb"
+)
+
+var sendRmCmd = &cobra.Command{
+	Use:   "rm @me/0x<hash>",
+	Short: "Remove a cast",
+	Long: `Removes one of your casts. "@me" stands for the fid in
+cast.fid (or --fid). Casts of other fids can not be removed.
+
+Use --thread to also remove the replies you posted under the
+cast, ex. all the parts of a long text that "post cast" split
+into a thread. At each level only your earliest reply is followed.`,
+	Run: runSendRm,
+}
+
+func runSendRm(cmd *cobra.Command, args []string) {
+	db.Open()
+	defer db.Close()
+
+	fid, privateKey, publicKey := signerFromFlags(cmd)
+	prepareFlag, _ := cmd.Flags().GetBool("prepare")
+	threadFlag, _ := cmd.Flags().GetBool("thread")
+
+	if len(args) == 0 {
+		log.Fatal("Missing arguments: @me/0x<hash> required")
+	}
+	uri := args[0]
+	if strings.HasPrefix(uri, "@me/") {
+		uri = "@" + strconv.FormatUint(fid, 10) + uri[len("@me"):]
+	}
+	user, parts := ParseFcURI(uri)
+	if user == nil {
+		log.Fatal("User not found")
+	}
+	if len(parts) != 1 || !strings.HasPrefix(parts[0], "0x") {
+		log.Fatal("Expected @me/0x<hash>")
+	}
+	if user.Fid != fid {
+		log.Fatalf("Cast belongs to fid %d, you can only remove casts of fid %d", user.Fid, fid)
+	}
+	hash := HashToBytes(parts[0])
+	if hash == nil {
+		log.Fatalf("Invalid hash: %s", parts[0])
+	}
+
+	hub := fctools.NewFarcasterHub()
+	defer hub.Close()
+
+	if _, err := hub.GetCast(fid, hash); err != nil {
+		log.Fatalf("Cast @%d/%s not found: %v", fid, parts[0], err)
+	}
+
+	hashes := []fctools.Hash{fctools.Hash(hash)}
+	if threadFlag {
+		castId := &pb.CastId{Fid: fid, Hash: hash}
+		hashes = fctools.NewCastGroup().FromCast(hub, castId, true).AuthorThread(fctools.Hash(hash))
+	}
+
+	// Remove the last reply first, so that an interrupted
+	// run never leaves orphan replies behind.
+	for i := len(hashes) - 1; i >= 0; i-- {
+		messageData := newMessageData("MESSAGE_TYPE_CAST_REMOVE", fid)
+		messageData.Body = &pb.MessageData_CastRemoveBody{
+			CastRemoveBody: &pb.CastRemoveBody{TargetHash: hashes[i].Bytes()},
+		}
+		message := fctools.CreateMessage(messageData, privateKey, publicKey)
+		if !prepareFlag {
+			log.Printf("Removing @%d/0x%s", fid, hex.EncodeToString(hashes[i].Bytes()))
+		}
+		submitOrPrint(hub, message, prepareFlag)
+	}
+}
+
+func init() {
+	sendCmd.AddCommand(sendRmCmd)
+	addSignerFlags(sendRmCmd)
+	sendRmCmd.Flags().BoolP("thread", "", false, "Also remove your replies under the cast")
+}
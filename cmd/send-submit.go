@@ -0,0 +1,106 @@
+package cmd
+
+import (
+	"encoding/hex"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/spf13/cobra"
+	pb "github.com/vrypan/fargo/farcaster"
+	"github.com/vrypan/fargo/fctools"
+	db "github.com/vrypan/fargo/localdb"
+)
+
+var sendSubmitCmd = &cobra.Command{
+	Use:   "submit [file...]",
+	Short: "Submit pre-signed messages",
+	Long: `Submits messages created with "post ... --prepare".
+Messages are read from the files given, or from stdin.
+
+This makes it possible to sign messages on one machine,
+and submit them from another:
+
+fargo post cast --prepare "Hello" > hello.json
+fargo post submit hello.json
+
+Messages are verified locally before they are submitted.
+More than one message is sent as a single bulk request.`,
+	Run: runSendSubmit,
+}
+
+func runSendSubmit(cmd *cobra.Command, args []string) {
+	db.Open()
+	defer db.Close()
+
+	if len(args) == 0 {
+		args = []string{"-"}
+	}
+
+	hub := fctools.NewFarcasterHub()
+	defer hub.Close()
+
+	var messages []*pb.Message
+	for _, arg := range args {
+		m, err := loadMessages(hub, arg)
+		if err != nil {
+			log.Fatalf("%s: %v", arg, err)
+		}
+		messages = append(messages, m...)
+	}
+	if len(messages) == 0 {
+		log.Fatal("No messages found")
+	}
+
+	checked := make(map[string]bool)
+	data := make([]*pb.MessageData, 0, len(messages))
+	for _, message := range messages {
+		// message.Data can not be trusted before this: VerifyMessage
+		// checks that it matches the signed data_bytes.
+		if err := fctools.VerifyMessage(message); err != nil {
+			log.Fatalf("0x%s: %v", hex.EncodeToString(message.Hash), err)
+		}
+		signer := fmt.Sprintf("%d/%x", message.Data.Fid, message.Signer)
+		if !checked[signer] {
+			if err := checkSigner(hub, message.Data.Fid, message.Signer); err != nil {
+				log.Fatal(err)
+			}
+			checked[signer] = true
+		}
+		data = append(data, message.Data)
+	}
+	checkStorageOf(cmd, hub, data)
+
+	if len(messages) == 1 {
+		msg, err := hub.SubmitMessage(messages[0])
+		if err != nil {
+			log.Fatalf("Error submitting message: %v", err)
+		}
+		fmt.Printf("Sent: @%d/0x%s\n", msg.Data.Fid, hex.EncodeToString(msg.Hash))
+		return
+	}
+
+	res, err := hub.SubmitBulkMessages(messages)
+	if err != nil {
+		log.Fatalf("Error submitting messages: %v", err)
+	}
+	failed := 0
+	for i, r := range res.Messages {
+		if msgErr := r.GetMessageError(); msgErr != nil {
+			fmt.Printf("Error: @%d/0x%s: %s %s\n",
+				messages[i].Data.Fid, hex.EncodeToString(msgErr.Hash), msgErr.ErrCode, msgErr.Message)
+			failed++
+			continue
+		}
+		msg := r.GetMessage()
+		fmt.Printf("Sent: @%d/0x%s\n", msg.Data.Fid, hex.EncodeToString(msg.Hash))
+	}
+	if failed > 0 {
+		os.Exit(1)
+	}
+}
+
+func init() {
+	sendCmd.AddCommand(sendSubmitCmd)
+	sendSubmitCmd.Flags().BoolP("strict", "", false, "Don't submit if it would make the hub prune old messages")
+}
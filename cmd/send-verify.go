@@ -0,0 +1,158 @@
+package cmd
+
+import (
+	"encoding/hex"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	pb "github.com/vrypan/fargo/farcaster"
+	"github.com/vrypan/fargo/fctools"
+	db "github.com/vrypan/fargo/localdb"
+)
+
+var sendVerifyAddressCmd = &cobra.Command{
+	Use:   "verify-address [0x<address>]",
+	Short: "Link an Ethereum address to your fid",
+	Long: `Creates a VERIFICATION_ADD_ETH_ADDRESS message.
+
+The verification claim is an EIP-712 message signed by the address
+being verified. It includes the hash of a recent Ethereum block,
+passed with --block-hash.
+
+The claim can be signed locally with the address' private key, passed
+with --eth-privkey or the FARGO_ETH_PRIVKEY environment variable.
+Alternatively, pass the address and a claim signature produced
+elsewhere with --claim-signature.`,
+	Run: runSendVerifyAddress,
+}
+
+var sendUnverifyCmd = &cobra.Command{
+	Use:   "unverify <address>",
+	Short: "Remove a verified Ethereum (0x...) or Solana address",
+	Run:   runSendUnverify,
+}
+
+func hexFlag(cmd *cobra.Command, name string) []byte {
+	s, _ := cmd.Flags().GetString(name)
+	if s == "" {
+		return nil
+	}
+	b, err := hex.DecodeString(strings.TrimPrefix(s, "0x"))
+	if err != nil {
+		log.Fatalf("--%s: %v", name, err)
+	}
+	return b
+}
+
+func runSendVerifyAddress(cmd *cobra.Command, args []string) {
+	db.Open()
+	defer db.Close()
+
+	fid, privateKey, publicKey := signerFromFlags(cmd)
+	prepareFlag, _ := cmd.Flags().GetBool("prepare")
+
+	blockHash := hexFlag(cmd, "block-hash")
+	if len(blockHash) != 32 {
+		log.Fatal("--block-hash is required: the 32-byte hash of a recent Ethereum block")
+	}
+	ethKey := hexFlag(cmd, "eth-privkey")
+	if ethKey == nil && os.Getenv("FARGO_ETH_PRIVKEY") != "" {
+		var err error
+		if ethKey, err = hex.DecodeString(strings.TrimPrefix(os.Getenv("FARGO_ETH_PRIVKEY"), "0x")); err != nil {
+			log.Fatalf("FARGO_ETH_PRIVKEY: %v", err)
+		}
+	}
+	claimSignature := hexFlag(cmd, "claim-signature")
+
+	var address []byte
+	if len(args) > 0 {
+		protocol, a, err := fctools.ParseAddress(args[0])
+		if err != nil {
+			log.Fatal(err)
+		}
+		if protocol != pb.Protocol_PROTOCOL_ETHEREUM {
+			log.Fatal("Only Ethereum addresses can be verified")
+		}
+		address = a
+	}
+
+	messageData := newMessageData("MESSAGE_TYPE_VERIFICATION_ADD_ETH_ADDRESS", fid)
+	switch {
+	case ethKey != nil && claimSignature != nil:
+		log.Fatal("Use either --eth-privkey or --claim-signature, not both")
+	case ethKey != nil:
+		signedAddress, signature, err := fctools.SignVerificationClaim(ethKey, fid, blockHash, messageData.Network)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if address != nil && !strings.EqualFold(hex.EncodeToString(address), hex.EncodeToString(signedAddress)) {
+			log.Fatalf("The private key belongs to %s, not %s", fctools.EthChecksumAddress(signedAddress), args[0])
+		}
+		address, claimSignature = signedAddress, signature
+	case claimSignature != nil:
+		if address == nil {
+			log.Fatal("The address is required when using --claim-signature")
+		}
+		if err := fctools.VerifyClaimSignature(fid, address, blockHash, messageData.Network, claimSignature); err != nil {
+			log.Fatal(err)
+		}
+	default:
+		log.Fatal("One of --eth-privkey, FARGO_ETH_PRIVKEY or --claim-signature is required")
+	}
+
+	messageData.Body = &pb.MessageData_VerificationAddAddressBody{
+		VerificationAddAddressBody: &pb.VerificationAddAddressBody{
+			Address:        address,
+			ClaimSignature: claimSignature,
+			BlockHash:      blockHash,
+			Protocol:       pb.Protocol_PROTOCOL_ETHEREUM,
+		},
+	}
+
+	hub := fctools.NewFarcasterHub()
+	defer hub.Close()
+	checkStorage(cmd, hub, fid, "MESSAGE_TYPE_VERIFICATION_ADD_ETH_ADDRESS", 1)
+	message := fctools.CreateMessage(messageData, privateKey, publicKey)
+	submitOrPrint(hub, message, prepareFlag)
+}
+
+func runSendUnverify(cmd *cobra.Command, args []string) {
+	db.Open()
+	defer db.Close()
+
+	fid, privateKey, publicKey := signerFromFlags(cmd)
+	prepareFlag, _ := cmd.Flags().GetBool("prepare")
+
+	if len(args) == 0 {
+		log.Fatal("Missing arguments: address required")
+	}
+	protocol, address, err := fctools.ParseAddress(args[0])
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	hub := fctools.NewFarcasterHub()
+	defer hub.Close()
+
+	messageData := newMessageData("MESSAGE_TYPE_VERIFICATION_REMOVE", fid)
+	messageData.Body = &pb.MessageData_VerificationRemoveBody{
+		VerificationRemoveBody: &pb.VerificationRemoveBody{
+			Address:  address,
+			Protocol: protocol,
+		},
+	}
+	message := fctools.CreateMessage(messageData, privateKey, publicKey)
+	submitOrPrint(hub, message, prepareFlag)
+}
+
+func init() {
+	sendCmd.AddCommand(sendVerifyAddressCmd)
+	sendCmd.AddCommand(sendUnverifyCmd)
+	addSignerFlags(sendVerifyAddressCmd)
+	addSignerFlags(sendUnverifyCmd)
+	sendVerifyAddressCmd.Flags().StringP("block-hash", "", "", "Hash of a recent Ethereum block (0x...)")
+	sendVerifyAddressCmd.Flags().StringP("eth-privkey", "", "", "Private key of the Ethereum address (0x...)")
+	sendVerifyAddressCmd.Flags().StringP("claim-signature", "", "", "Pre-computed EIP-712 claim signature (0x...)")
+}
@@ -1,7 +1,16 @@
 package cmd
 
 import (
+	"encoding/hex"
+	"fmt"
+	"log"
+	"time"
+
 	"github.com/spf13/cobra"
+	"github.com/vrypan/fargo/config"
+	pb "github.com/vrypan/fargo/farcaster"
+	"github.com/vrypan/fargo/fctools"
+	"github.com/vrypan/fargo/keystore"
 )
 
 // sendCmd represents the send command
@@ -17,3 +26,198 @@ var sendCmd = &cobra.Command{
 func init() {
 	rootCmd.AddCommand(sendCmd)
 }
+
+// addSignerFlags adds the flags used by all "post" subcommands
+// to select the fid and app key that sign the message.
+func addSignerFlags(c *cobra.Command) {
+	c.Flags().Uint64P("fid", "", 0, "Fid who is posting")
+	c.Flags().StringP("pubkey", "", "", "Application public key. Ex: 0xdef1234....")
+	c.Flags().StringP("privkey", "", "", "Application private key. Ex: 0xabc1234....")
+	c.Flags().StringP("key", "", "", "Name of a key in the keystore (see \"fargo keys\"). Overrides cast.key")
+	c.Flags().BoolP("prepare", "", false, "Prepare the Message object and print it, but don't send it")
+	c.Flags().BoolP("strict", "", false, "Don't post if it would make the hub prune old messages")
+}
+
+/*
+loadSigner returns the fid and keys used to sign messages.
+Values passed as flags override the cast.* config values.
+If a keystore key is selected (--key or cast.key), and no
+--privkey is given, the keys are read from the keystore.
+*/
+func loadSigner(cmd *cobra.Command) (uint64, []byte, []byte, error) {
+	var err error
+	var privateKey []byte
+	var publicKey []byte
+	var s string
+
+	keyName := config.GetString("cast.key")
+	if c, _ := cmd.Flags().GetString("key"); c != "" {
+		keyName = c
+	}
+	privkeyFlag, _ := cmd.Flags().GetString("privkey")
+
+	if keyName != "" && privkeyFlag == "" {
+		key, err := keystore.Load(keyName)
+		if err != nil {
+			return 0, nil, nil, fmt.Errorf("Key error: %s: %v", keyName, err)
+		}
+		privateKey, publicKey, err = key.Decrypt(readPassphrase("Passphrase for "+keyName+": ", false))
+		if err != nil {
+			return 0, nil, nil, fmt.Errorf("Key error: %s: %v", keyName, err)
+		}
+	} else {
+		s = config.GetString("cast.privkey")
+		if privkeyFlag != "" {
+			s = privkeyFlag
+		}
+		if len(s) < 2 {
+			return 0, nil, nil, fmt.Errorf("Private key error: private key string too short")
+		}
+		if privateKey, err = hex.DecodeString(s[2:]); err != nil {
+			return 0, nil, nil, fmt.Errorf("Private key error: %v", err)
+		}
+
+		s = config.GetString("cast.pubkey")
+		if c, _ := cmd.Flags().GetString("pubkey"); c != "" {
+			s = c
+		}
+		if len(s) < 2 {
+			return 0, nil, nil, fmt.Errorf("Public key error: public key string too short")
+		}
+		if publicKey, err = hex.DecodeString(s[2:]); err != nil {
+			return 0, nil, nil, fmt.Errorf("Public key error: %v", err)
+		}
+	}
+
+	fid := uint64(config.GetInt("cast.fid"))
+	if c, _ := cmd.Flags().GetUint64("fid"); c > 0 {
+		fid = c
+	}
+	if fid == 0 {
+		return 0, nil, nil, fmt.Errorf("No fid: fid is zero")
+	}
+	return fid, privateKey, publicKey, nil
+}
+
+// signerFromFlags is like loadSigner, but exits on error.
+func signerFromFlags(cmd *cobra.Command) (uint64, []byte, []byte) {
+	fid, privateKey, publicKey, err := loadSigner(cmd)
+	if err != nil {
+		log.Fatalf("%v\nUse --help to see options.", err)
+	}
+	return fid, privateKey, publicKey
+}
+
+// newMessageData returns a mainnet MessageData with the current timestamp.
+func newMessageData(messageType string, fid uint64) *pb.MessageData {
+	return &pb.MessageData{
+		Type:      pb.MessageType(pb.MessageType_value[messageType]),
+		Fid:       fid,
+		Timestamp: uint32(time.Now().Unix() - fctools.FARCASTER_EPOCH),
+		Network:   pb.FarcasterNetwork(pb.FarcasterNetwork_value["FARCASTER_NETWORK_MAINNET"]),
+	}
+}
+
+/*
+checkSigner makes sure that signer is an active signer of fid,
+so that we can fail with a clear message instead of a hub error.
+The hub is always asked: a cached result would let a revoked key through.
+*/
+func checkSigner(hub *fctools.FarcasterHub, fid uint64, signer []byte) error {
+	_, err := hub.GetOnChainSigner(fid, signer)
+	if err == fctools.ERR_SIGNER_NOT_ACTIVE {
+		return fmt.Errorf("Key 0x%x is not an active signer of fid %d. See \"fargo keys check --help\"", signer, fid)
+	}
+	if err != nil {
+		return fmt.Errorf("Error checking signer: %v", err)
+	}
+	return nil
+}
+
+/*
+checkStorage warns when posting n messages of messageType would
+push the oldest messages of fid out of storage. With --strict
+it exits instead. Nothing is checked when using --prepare.
+*/
+func checkStorage(cmd *cobra.Command, hub *fctools.FarcasterHub, fid uint64, messageType string, n int) {
+	if prepare, _ := cmd.Flags().GetBool("prepare"); prepare {
+		return
+	}
+	storeType := fctools.StoreTypeOf(pb.MessageType(pb.MessageType_value[messageType]))
+	if storeType == pb.StoreType_STORE_TYPE_NONE {
+		return
+	}
+	usage, err := hub.GetStoreUsage(fid, storeType)
+	if err != nil {
+		log.Printf("Could not check storage: %v", err)
+		return
+	}
+	pruned := usage.Pruned(uint64(n))
+	if pruned == 0 {
+		return
+	}
+	msg := fmt.Sprintf("The %s store of fid %d is full (%d/%d). Posting will prune the %d oldest message(s). See \"fargo storage\"",
+		usage.Store, fid, usage.Used, usage.Limit, pruned)
+	if strict, _ := cmd.Flags().GetBool("strict"); strict {
+		log.Fatal(msg)
+	}
+	log.Print("Warning: " + msg)
+}
+
+/*
+checkStorageOf runs checkStorage for the messages with data, by fid
+and message type. Messages that replace one the fid already has
+(see FarcasterHub.Replaces) don't use more storage, and are not counted.
+*/
+func checkStorageOf(cmd *cobra.Command, hub *fctools.FarcasterHub, data []*pb.MessageData) {
+	if prepare, _ := cmd.Flags().GetBool("prepare"); prepare {
+		return
+	}
+	type storeKey struct {
+		fid         uint64
+		messageType string
+	}
+	keys := make([]storeKey, 0)
+	stores := make(map[storeKey]int)
+	for _, d := range data {
+		key := storeKey{d.Fid, d.Type.String()}
+		if _, ok := stores[key]; !ok {
+			keys = append(keys, key)
+			stores[key] = 0
+		}
+		if replaces, err := hub.Replaces(d); err == nil && replaces {
+			continue
+		}
+		stores[key]++
+	}
+	for _, key := range keys {
+		if stores[key] > 0 {
+			checkStorage(cmd, hub, key.fid, key.messageType, stores[key])
+		}
+	}
+}
+
+/*
+submitOrPrint submits message to the hub, or, if prepare is true,
+prints it as JSON without sending it.
+*/
+func submitOrPrint(hub *fctools.FarcasterHub, message *pb.Message, prepare bool) {
+	if prepare {
+		jsonData, err := fctools.Marshal(
+			message, fctools.MarshalOptions{Bytes2Hash: true, Timestamp2Date: false},
+		)
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Println(string(jsonData))
+		return
+	}
+	if err := checkSigner(hub, message.Data.Fid, message.Signer); err != nil {
+		log.Fatal(err)
+	}
+	msg, err := hub.SubmitMessage(message)
+	if err != nil {
+		log.Fatalf("Error submitting message: %v", err)
+	}
+	fmt.Printf("Sent: @%d/0x%s\n", msg.Data.Fid, hex.EncodeToString(msg.Hash))
+}
@@ -43,6 +43,8 @@ func getSnapshot(cmd *cobra.Command, args []string) {
 	}
 	expandFlag, _ := cmd.Flags().GetBool("recursive")
 	outFlag, _ := cmd.Flags().GetString("out")
+	maxDepthFlag, _ := cmd.Flags().GetInt("max-depth")
+	maxCastsFlag, _ := cmd.Flags().GetInt("max-casts")
 
 	/*
 		Create the output directory
@@ -72,7 +74,7 @@ func getSnapshot(cmd *cobra.Command, args []string) {
 	defer db.Close()
 
 	log.Println("Fetching casts...")
-	casts := fctools.NewCastGroup().FromCastFidHash(hub, user.Fid, parts[0][2:], expandFlag)
+	casts := fctools.NewCastGroup().WithLimits(maxDepthFlag, maxCastsFlag).FromCastFidHash(hub, user.Fid, parts[0][2:], expandFlag)
 	if casts == nil || len(casts.Messages) == 0 {
 		log.Fatalf("Failed to get cast or thread %s", parts[0])
 	}
@@ -152,5 +154,7 @@ func getSnapshot(cmd *cobra.Command, args []string) {
 func init() {
 	rootCmd.AddCommand(snapshotCmd)
 	snapshotCmd.Flags().BoolP("recursive", "r", true, "Recursively get parent casts and replies")
+	snapshotCmd.Flags().IntP("max-depth", "", 0, "Used with --recursive to limit how many levels of replies are fetched (0: no limit)")
+	snapshotCmd.Flags().IntP("max-casts", "", 0, "Used with --recursive to limit how many casts of a thread are fetched (0: no limit)")
 	snapshotCmd.Flags().StringP("out", "", "", "Output directory")
 }
@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+
+	"github.com/spf13/cobra"
+	"github.com/vrypan/fargo/config"
+	"github.com/vrypan/fargo/fctools"
+	db "github.com/vrypan/fargo/localdb"
+	"github.com/vrypan/fargo/tui"
+)
+
+var storageCmd = &cobra.Command{
+	Use:   "storage [@username]",
+	Short: "Show storage usage",
+	Long: `Shows the messages used and allowed in each store.
+When a store is full, the hub prunes its oldest messages
+to make room for new ones.
+
+If @username is omitted, cast.fid is used.`,
+	Run: storageRun,
+}
+
+func storageRun(cmd *cobra.Command, args []string) {
+	jsonFlag, _ := cmd.Flags().GetBool("json")
+
+	db.Open()
+	defer db.Close()
+	hub := fctools.NewFarcasterHub()
+	defer hub.Close()
+
+	fid := uint64(config.GetInt("cast.fid"))
+	if len(args) > 0 {
+		user, parts := ParseFcURI(args[0])
+		if user == nil || len(parts) > 0 {
+			log.Fatal("User not found. The expected format is @username")
+		}
+		fid = user.Fid
+	}
+	if fid == 0 {
+		log.Fatal("No fid: set cast.fid or use @username")
+	}
+
+	limits, err := hub.GetCurrentStorageLimitsByFid(fid)
+	if err != nil {
+		log.Fatal("Error fetching storage limits: ", err)
+	}
+	usage := fctools.StorageUsage(limits)
+	if jsonFlag {
+		b, _ := json.MarshalIndent(usage, "", "  ")
+		fmt.Println(string(b))
+		return
+	}
+	fmt.Print(tui.PpStorage(usage))
+}
+
+func init() {
+	rootCmd.AddCommand(storageCmd)
+	storageCmd.Flags().BoolP("json", "", false, "Generate a json object instead of text")
+}
@@ -9,6 +9,7 @@ import (
 	"os/exec"
 	"runtime"
 
+	"github.com/vrypan/fargo/config"
 	"github.com/vrypan/fargo/fctools"
 )
 
@@ -29,6 +30,27 @@ func ParseFcURI(uri string) (*fctools.User, []string) {
 	return user, parts[1:]
 }
 
+// IsChannelURI returns true for "channel:<url or alias>" and "~<alias>".
+func IsChannelURI(uri string) bool {
+	return strings.HasPrefix(uri, "channel:") || strings.HasPrefix(uri, "~")
+}
+
+/*
+ChannelUrl returns the parent URL of a channel URI.
+Aliases are looked up in the "channels" config map. Unknown
+aliases are assumed to be Warpcast channel ids.
+*/
+func ChannelUrl(uri string) string {
+	name := strings.TrimPrefix(strings.TrimPrefix(uri, "channel:"), "~")
+	if strings.Contains(name, "://") {
+		return name
+	}
+	if url, ok := config.GetStringMapString("channels")[strings.ToLower(name)]; ok {
+		return url
+	}
+	return "https://warpcast.com/~/channel/" + name
+}
+
 // Convert "0xhash" to []byte
 func HashToBytes(hash string) []byte {
 	if hash_bytes, err := hex.DecodeString(hash[2:]); err != nil {
@@ -0,0 +1,120 @@
+package cmd
+
+import (
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	pb "github.com/vrypan/fargo/farcaster"
+	"github.com/vrypan/fargo/fctools"
+)
+
+var verifyCmd = &cobra.Command{
+	Use:   "verify [file.json|@username/0x<hash>|-]...",
+	Short: "Verify message hashes and signatures",
+	Long: `Recomputes the hash of each message and checks its Ed25519
+signature locally, without trusting any hub.
+
+Files can contain the output of "post ... --prepare", "get --json"
+or a snapshot's thread.json. Use "-" to read from stdin.
+
+Use --hub to also ask the hub to validate the messages.
+Note that a valid signature does not prove that the signer
+belongs to the fid: use "fargo keys check --pubkey" for that.`,
+	Run: verifyRun,
+}
+
+func verifyRun(cmd *cobra.Command, args []string) {
+	hubFlag, _ := cmd.Flags().GetBool("hub")
+	if len(args) == 0 {
+		log.Fatal("Missing arguments: file or @username/0x<hash> required")
+	}
+
+	// Files are verified offline: the hub is only opened when needed.
+	var hub *fctools.FarcasterHub
+	getHub := func() *fctools.FarcasterHub {
+		if hub == nil {
+			hub = fctools.NewFarcasterHub()
+		}
+		return hub
+	}
+	defer func() {
+		if hub != nil {
+			hub.Close()
+		}
+	}()
+
+	failed := 0
+	for _, arg := range args {
+		var argHub *fctools.FarcasterHub
+		if strings.HasPrefix(arg, "@") {
+			argHub = getHub()
+		}
+		messages, err := loadMessages(argHub, arg)
+		if err != nil {
+			log.Fatalf("%s: %v", arg, err)
+		}
+		for _, message := range messages {
+			id := fmt.Sprintf("@%d/0x%s", message.Data.GetFid(), hex.EncodeToString(message.Hash))
+			if err := fctools.VerifyMessage(message); err != nil {
+				fmt.Printf("%s FAILED: %v\n", id, err)
+				failed++
+				continue
+			}
+			if hubFlag {
+				res, err := getHub().ValidateMessage(message)
+				if err != nil || !res.Valid {
+					fmt.Printf("%s FAILED hub validation: %v\n", id, err)
+					failed++
+					continue
+				}
+			}
+			fmt.Printf("%s OK (signer 0x%s)\n", id, hex.EncodeToString(message.Signer))
+		}
+	}
+	if failed > 0 {
+		os.Exit(1)
+	}
+}
+
+/*
+loadMessages reads messages from a JSON file, stdin ("-")
+or, for @username/0x<hash>, from the hub. hub is only
+used for @username/0x<hash>, and can be nil otherwise.
+*/
+func loadMessages(hub *fctools.FarcasterHub, arg string) ([]*pb.Message, error) {
+	if strings.HasPrefix(arg, "@") {
+		user, parts := ParseFcURI(arg)
+		if user == nil {
+			return nil, fmt.Errorf("User not found")
+		}
+		if len(parts) != 1 || !strings.HasPrefix(parts[0], "0x") {
+			return nil, fmt.Errorf("Expected @username/0x<hash>")
+		}
+		message, err := hub.GetCast(user.Fid, HashToBytes(parts[0]))
+		if err != nil {
+			return nil, err
+		}
+		return []*pb.Message{message}, nil
+	}
+	var b []byte
+	var err error
+	if arg == "-" {
+		b, err = io.ReadAll(os.Stdin)
+	} else {
+		b, err = os.ReadFile(arg)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return fctools.UnmarshalMessages(b)
+}
+
+func init() {
+	rootCmd.AddCommand(verifyCmd)
+	verifyCmd.Flags().BoolP("hub", "", false, "Also validate the messages with the hub's ValidateMessage")
+}
@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/vrypan/fargo/config"
+	pb "github.com/vrypan/fargo/farcaster"
+	"github.com/vrypan/fargo/fctools"
+	db "github.com/vrypan/fargo/localdb"
+	"github.com/vrypan/fargo/tui"
+)
+
+var watchCmd = &cobra.Command{
+	Use:   "watch",
+	Short: "Follow new messages as they reach the hub",
+	Long: `Prints messages as they are merged by the hub, like "tail -f".
+
+Examples:
+fargo watch --fid @vrypan
+fargo watch --type cast-add --mention @vrypan
+fargo watch --parent-url chain://eip155:1/erc721:0x... --json
+
+Use --from to start from an older event id. If the connection
+drops, watch reconnects and resumes from the last event seen.`,
+	Run: watchRun,
+}
+
+func watchRun(cmd *cobra.Command, args []string) {
+	fidFlag, _ := cmd.Flags().GetStringSlice("fid")
+	typeFlag, _ := cmd.Flags().GetStringSlice("type")
+	parentUrlFlag, _ := cmd.Flags().GetString("parent-url")
+	mentionFlag, _ := cmd.Flags().GetString("mention")
+	fromFlag, _ := cmd.Flags().GetUint64("from")
+	jsonFlag, _ := cmd.Flags().GetBool("json")
+
+	db.Open()
+	defer db.Close()
+	hub := fctools.NewFarcasterHub()
+	defer hub.Close()
+
+	if IsChannelURI(parentUrlFlag) {
+		parentUrlFlag = ChannelUrl(parentUrlFlag)
+	}
+	filter := &fctools.EventFilter{ParentUrl: parentUrlFlag}
+	for _, f := range fidFlag {
+		filter.Fids = append(filter.Fids, userFid(hub, f))
+	}
+	for _, t := range typeFlag {
+		filter.MessageTypes = append(filter.MessageTypes, parseMessageType(t))
+	}
+	if mentionFlag != "" {
+		filter.Mention = userFid(hub, mentionFlag)
+	}
+
+	opts := &tui.FmtCastOpts{Width: config.GetInt("pprint.width")}
+	err := hub.Watch(fromFlag, filter, func(event *pb.HubEvent, message *pb.Message) bool {
+		if jsonFlag {
+			b, err := fctools.Marshal(event, fctools.MarshalOptions{Bytes2Hash: true})
+			if err != nil {
+				log.Printf("Error converting event %d to json: %v", event.Id, err)
+				return true
+			}
+			var line bytes.Buffer
+			json.Compact(&line, b)
+			fmt.Println(line.String())
+			return true
+		}
+		casts := fctools.NewCastGroup()
+		casts.Messages[fctools.Hash(message.Hash)] = &fctools.Cast{Message: message}
+		casts.CollectFnames(hub)
+		if message.Data.Type == pb.MessageType_MESSAGE_TYPE_CAST_ADD {
+			fmt.Print(tui.FmtCast(message, casts.Fnames, 0, true, opts))
+		} else {
+			fmt.Printf("%s %s\n", tui.PpFname(casts.Fnames[message.Data.Fid]), message.Data.Type)
+		}
+		return true
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+// userFid accepts a fid, an fname or an @fname.
+func userFid(hub *fctools.FarcasterHub, s string) uint64 {
+	user := fctools.NewUser().FromFname(hub, strings.TrimPrefix(s, "@"))
+	if user == nil {
+		log.Fatalf("User not found: %s", s)
+	}
+	return user.Fid
+}
+
+// parseMessageType accepts "cast-add", "CAST_ADD" or "MESSAGE_TYPE_CAST_ADD".
+func parseMessageType(s string) pb.MessageType {
+	name := strings.ToUpper(strings.ReplaceAll(s, "-", "_"))
+	if !strings.HasPrefix(name, "MESSAGE_TYPE_") {
+		name = "MESSAGE_TYPE_" + name
+	}
+	t, ok := pb.MessageType_value[name]
+	if !ok {
+		log.Fatalf("Unknown message type: %s", s)
+	}
+	return pb.MessageType(t)
+}
+
+func init() {
+	rootCmd.AddCommand(watchCmd)
+	watchCmd.Flags().StringSliceP("fid", "", nil, "Only show messages from these fids or @fnames")
+	watchCmd.Flags().StringSliceP("type", "", nil, "Only show these message types. Ex: cast-add,reaction-add")
+	watchCmd.Flags().StringP("parent-url", "", "", "Only show casts replying to this URL, or channel (~alias)")
+	watchCmd.Flags().StringP("mention", "", "", "Only show casts mentioning this fid or @fname")
+	watchCmd.Flags().Uint64P("from", "", 0, "Start from this event id, instead of now")
+	watchCmd.Flags().BoolP("json", "", false, "Print events as JSON, one per line")
+}
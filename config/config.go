@@ -27,16 +27,28 @@ func Load() string { // Load config and return config file path
 	defaultDownload := "~/Downloads"
 
 	defaults := map[string]interface{}{
-		"hub.host":     "hoyt.farcaster.xyz",
-		"hub.port":     "2283",
-		"hub.ssl":      "true",
-		"download.dir": defaultDownload,
-		"get.count":    20,
-		"cast.fid":     0,
-		"cast.privkey": "",
-		"cast.pubkey":  "",
-		"db.ttlhours":  24,
-		"pprint.width": 80,
+		"hub.host":         "hoyt.farcaster.xyz",
+		"hub.port":         "2283",
+		"hub.ssl":          "true",
+		"hub.endpoints":    []string{},
+		"hub.spread_reads": false,
+		"hub.healthcheck":  true,
+		"hub.timeout":      30,
+		"hub.retries":      2,
+		"hub.apikey":       "",
+		"hub.headers":      map[string]string{},
+		"hub.ca_file":      "",
+		"hub.cert_file":    "",
+		"hub.key_file":     "",
+		"hub.server_name":  "",
+		"download.dir":     defaultDownload,
+		"get.count":        20,
+		"cast.fid":         0,
+		"cast.key":         "",
+		"cast.privkey":     "",
+		"cast.pubkey":      "",
+		"db.ttlhours":      24,
+		"pprint.width":     80,
 	}
 	for key, value := range defaults {
 		viper.SetDefault(key, value)
@@ -63,8 +75,10 @@ func Load() string { // Load config and return config file path
 }
 
 var (
-	GetString = viper.GetString
-	GetInt    = viper.GetInt
-	GetBool   = viper.GetBool
-	BindPFlag = viper.BindPFlag
+	GetString          = viper.GetString
+	GetStringMapString = viper.GetStringMapString
+	GetInt             = viper.GetInt
+	GetBool            = viper.GetBool
+	GetStringSlice     = viper.GetStringSlice
+	BindPFlag          = viper.BindPFlag
 )
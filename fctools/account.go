@@ -0,0 +1,103 @@
+package fctools
+
+import (
+	"time"
+
+	pb "github.com/vrypan/fargo/farcaster"
+)
+
+/*
+Account is a summary of the on-chain state of an fid:
+the IdRegistry registration, its signers and its storage.
+*/
+type Account struct {
+	Fid             uint64          `json:"fid"`
+	CustodyAddress  string          `json:"custodyAddress"`
+	RecoveryAddress string          `json:"recoveryAddress"`
+	RegisteredBlock uint32          `json:"registeredBlock"`
+	RegisteredAt    time.Time       `json:"registeredAt"`
+	Signers         []AccountSigner `json:"signers"`
+	StorageUnits    uint32          `json:"storageUnits"`
+	Storage         []StoreUsage    `json:"storage"`
+}
+
+type AccountSigner struct {
+	Key     string    `json:"key"`
+	KeyType string    `json:"keyType"`
+	AppFid  uint64    `json:"appFid,omitempty"`
+	Block   uint32    `json:"block"`
+	AddedAt time.Time `json:"addedAt"`
+}
+
+// GetIdRegistryOnChainEvent returns the latest IdRegistry event of fid.
+func (hub FarcasterHub) GetIdRegistryOnChainEvent(fid uint64) (*pb.OnChainEvent, error) {
+	return hub.client.GetIdRegistryOnChainEvent(hub.ctx, &pb.FidRequest{Fid: fid})
+}
+
+// GetOnChainEvents returns all the on-chain events of eventType for fid.
+func (hub FarcasterHub) GetOnChainEvents(fid uint64, eventType pb.OnChainEventType) ([]*pb.OnChainEvent, error) {
+	events := make([]*pb.OnChainEvent, 0)
+	var pageToken []byte
+	for {
+		res, err := hub.client.GetOnChainEvents(hub.ctx,
+			&pb.OnChainEventRequest{Fid: fid, EventType: eventType, PageToken: pageToken},
+		)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, res.Events...)
+		pageToken = res.GetNextPageToken()
+		if len(pageToken) == 0 || len(res.Events) == 0 {
+			return events, nil
+		}
+	}
+}
+
+// FetchAccount collects the on-chain state of fid.
+func FetchAccount(hub *FarcasterHub, fid uint64) (*Account, error) {
+	account := &Account{Fid: fid, Signers: make([]AccountSigner, 0)}
+
+	latest, err := hub.GetIdRegistryOnChainEvent(fid)
+	if err != nil {
+		return nil, err
+	}
+	account.CustodyAddress = EthChecksumAddress(latest.GetIdRegisterEventBody().GetTo())
+	account.RecoveryAddress = EthChecksumAddress(latest.GetIdRegisterEventBody().GetRecoveryAddress())
+
+	// The latest event may be a transfer or a recovery change,
+	// the registration is the REGISTER event.
+	events, err := hub.GetOnChainEvents(fid, pb.OnChainEventType_EVENT_TYPE_ID_REGISTER)
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range events {
+		if e.GetIdRegisterEventBody().GetEventType() == pb.IdRegisterEventType_ID_REGISTER_EVENT_TYPE_REGISTER {
+			account.RegisteredBlock = e.BlockNumber
+			account.RegisteredAt = time.Unix(int64(e.BlockTimestamp), 0).UTC()
+		}
+	}
+
+	signers, err := hub.GetOnChainSignersByFid(fid)
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range signers {
+		signer := Signer{Event: e}
+		appFid, _ := signer.AppFid()
+		account.Signers = append(account.Signers, AccountSigner{
+			Key:     signer.KeyHex(),
+			KeyType: signer.KeyType(),
+			AppFid:  appFid,
+			Block:   e.BlockNumber,
+			AddedAt: signer.AddedAt().UTC(),
+		})
+	}
+
+	limits, err := hub.GetCurrentStorageLimitsByFid(fid)
+	if err != nil {
+		return nil, err
+	}
+	account.StorageUnits = limits.GetUnits()
+	account.Storage = StorageUsage(limits)
+	return account, nil
+}
@@ -0,0 +1,111 @@
+package fctools
+
+/*
+Transport security and authentication for hubs:
+
+hub.ssl          use TLS with the system root CAs
+hub.ca_file      PEM file with the CA(s) that signed the hub certificate (implies TLS)
+hub.cert_file    client certificate (PEM), for mTLS (implies TLS)
+hub.key_file     client certificate key (PEM)
+hub.server_name  expected name in the hub certificate, if it is not the hub host
+hub.apikey       sent as x-api-key with every call
+hub.headers      map of extra metadata sent with every call
+*/
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/vrypan/fargo/config"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+)
+
+const API_KEY_HEADER = "x-api-key"
+
+// dialOptions returns the grpc.DialOptions for the configured hub security settings.
+func dialOptions() ([]grpc.DialOption, error) {
+	cred, err := transportCredentials()
+	if err != nil {
+		return nil, err
+	}
+	opts := []grpc.DialOption{grpc.WithTransportCredentials(cred)}
+	return append(opts, metadataOptions(hubMetadata())...), nil
+}
+
+func transportCredentials() (credentials.TransportCredentials, error) {
+	caFile := config.GetString("hub.ca_file")
+	certFile := config.GetString("hub.cert_file")
+	keyFile := config.GetString("hub.key_file")
+	if !config.GetBool("hub.ssl") && caFile == "" && certFile == "" {
+		return insecure.NewCredentials(), nil
+	}
+	return tlsCredentials(caFile, certFile, keyFile, config.GetString("hub.server_name"))
+}
+
+/*
+tlsCredentials returns TLS credentials that trust the CAs in caFile
+(or the system roots if caFile is empty), and present the client
+certificate in certFile/keyFile, if set.
+*/
+func tlsCredentials(caFile, certFile, keyFile, serverName string) (credentials.TransportCredentials, error) {
+	tlsConfig := &tls.Config{ServerName: serverName}
+	if caFile != "" {
+		pem, err := os.ReadFile(caFile)
+		if err != nil {
+			return nil, fmt.Errorf("hub.ca_file: %w", err)
+		}
+		tlsConfig.RootCAs = x509.NewCertPool()
+		if !tlsConfig.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("hub.ca_file: no certificates found in %s", caFile)
+		}
+	}
+	if certFile != "" || keyFile != "" {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("hub.cert_file/hub.key_file: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	return credentials.NewTLS(tlsConfig), nil
+}
+
+// hubMetadata returns the key/value pairs sent with every call.
+func hubMetadata() []string {
+	headers := config.GetStringMapString("hub.headers")
+	keys := make([]string, 0, len(headers))
+	for k := range headers {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	kv := make([]string, 0, 2*len(headers)+2)
+	for _, k := range keys {
+		kv = append(kv, strings.ToLower(k), headers[k])
+	}
+	if apiKey := config.GetString("hub.apikey"); apiKey != "" {
+		kv = append(kv, API_KEY_HEADER, apiKey)
+	}
+	return kv
+}
+
+// metadataOptions adds the metadata key/value pairs kv to every call.
+func metadataOptions(kv []string) []grpc.DialOption {
+	if len(kv) == 0 {
+		return nil
+	}
+	unary := func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		return invoker(metadata.AppendToOutgoingContext(ctx, kv...), method, req, reply, cc, opts...)
+	}
+	stream := func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		return streamer(metadata.AppendToOutgoingContext(ctx, kv...), desc, cc, method, opts...)
+	}
+	return []grpc.DialOption{grpc.WithChainUnaryInterceptor(unary), grpc.WithChainStreamInterceptor(stream)}
+}
@@ -0,0 +1,52 @@
+package fctools
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	pb "github.com/vrypan/fargo/farcaster"
+	"google.golang.org/grpc/metadata"
+)
+
+type metadataServer struct {
+	pb.UnimplementedHubServiceServer
+	md metadata.MD
+}
+
+func (s *metadataServer) GetInfo(ctx context.Context, req *pb.HubInfoRequest) (*pb.HubInfoResponse, error) {
+	s.md, _ = metadata.FromIncomingContext(ctx)
+	return &pb.HubInfoResponse{}, nil
+}
+
+func Test_MetadataOptions(t *testing.T) {
+	srv := &metadataServer{}
+	opts := metadataOptions([]string{API_KEY_HEADER, "secret", "x-team", "fargo"})
+	pool := &hubPool{endpoints: []*hubEndpoint{testEndpoint(t, "a", srv, opts...)}}
+	defer pool.Close()
+
+	if _, err := pb.NewHubServiceClient(pool).GetInfo(context.Background(), &pb.HubInfoRequest{}); err != nil {
+		t.Fatal(err)
+	}
+	if v := srv.md.Get(API_KEY_HEADER); len(v) != 1 || v[0] != "secret" {
+		t.Fatalf("Unexpected %s: %v", API_KEY_HEADER, v)
+	}
+	if v := srv.md.Get("x-team"); len(v) != 1 || v[0] != "fargo" {
+		t.Fatalf("Unexpected x-team: %v", v)
+	}
+}
+
+func Test_TLSCredentials(t *testing.T) {
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	os.WriteFile(caFile, []byte("not a certificate"), 0600)
+	if _, err := tlsCredentials(caFile, "", "", ""); err == nil {
+		t.Fatal("Expected an error for an invalid CA file")
+	}
+	if _, err := tlsCredentials("", "missing.pem", "missing.key", ""); err == nil {
+		t.Fatal("Expected an error for a missing client certificate")
+	}
+	if _, err := tlsCredentials("", "", "", "hub.example.com"); err != nil {
+		t.Fatal(err)
+	}
+}
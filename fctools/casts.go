@@ -1,9 +1,11 @@
 package fctools
 
 import (
+	"bytes"
 	"encoding/base64"
 	"encoding/hex"
 	"encoding/json"
+	"sort"
 	"strconv"
 	"time"
 
@@ -14,6 +16,14 @@ import (
 type Cast struct {
 	Message *pb.Message
 	Replies []Hash
+	Stats   *CastStats
+}
+
+// CastStats holds reaction and reply counts, see CastGroup.CollectStats.
+type CastStats struct {
+	Likes   int `json:"likes"`
+	Recasts int `json:"recasts"`
+	Replies int `json:"replies"`
 }
 
 func (c *Cast) String() string {
@@ -55,6 +65,11 @@ type CastGroup struct {
 	Messages map[Hash]*Cast
 	Fnames   map[uint64]string
 	Ordered  []Hash
+	// Limits for thread expansion, 0 means no limit. See WithLimits.
+	MaxDepth int
+	MaxCasts int
+	// The order of the casts, see SortBy.
+	Sort SortMode
 }
 
 func NewCastGroup() *CastGroup {
@@ -65,7 +80,19 @@ func NewCastGroup() *CastGroup {
 }
 
 /*
-Populates a CastGroup with recent casts from an Fid.
+WithLimits limits how far FromCast expands threads: replies more than
+maxDepth levels below the head are not fetched, and expansion stops
+once the group holds maxCasts casts. 0 means no limit.
+*/
+func (grp *CastGroup) WithLimits(maxDepth int, maxCasts int) *CastGroup {
+	grp.MaxDepth = maxDepth
+	grp.MaxCasts = maxCasts
+	return grp
+}
+
+/*
+Populates a CastGroup with the count most recent casts from an Fid.
+count == 0 fetches all the casts of the Fid.
 Head is set to nil.
 */
 func (grp *CastGroup) FromFid(hub *FarcasterHub, fid uint64, count uint32) *CastGroup {
@@ -77,19 +104,105 @@ func (grp *CastGroup) FromFid(hub *FarcasterHub, fid uint64, count uint32) *Cast
 	if err != nil {
 		return grp
 	}
+	grp.appendOrdered(messages)
+	grp.CollectFnames(hub)
+	return grp
+}
 
-	grp.Ordered = make([]Hash, len(messages))
-	var hash Hash
-	//var cast *pb.Message
-	for i, cast := range messages {
-		hash = Hash(cast.Hash[:])
-		grp.Messages[hash] = &Cast{Message: cast}
-		grp.Ordered[i] = hash
+/*
+Populates a CastGroup with the count most recent casts whose parent is url
+(ex. the casts in a channel).
+count == 0 fetches all of them.
+Head is set to nil.
+*/
+func (grp *CastGroup) FromParentUrl(hub *FarcasterHub, url string, count uint32) *CastGroup {
+	if hub == nil {
+		hub = NewFarcasterHub()
+		defer hub.Close()
 	}
+	messages, err := hub.GetCastsByParentUrl(url, count)
+	if err != nil {
+		return grp
+	}
+	grp.appendOrdered(messages)
 	grp.CollectFnames(hub)
 	return grp
 }
 
+/*
+Populates a CastGroup with the count most recent casts mentioning an Fid.
+count == 0 fetches all of them.
+
+If since > 0, count is ignored and all the casts newer than the Farcaster
+timestamp since are fetched, plus the casts posted at since that are
+not in seen (the casts already seen at that timestamp, see NewestHashes).
+Head is set to nil. On error, the group holds the casts fetched so far.
+*/
+func (grp *CastGroup) FromMentions(hub *FarcasterHub, fid uint64, count uint32, since uint32, seen map[Hash]bool) (*CastGroup, error) {
+	if hub == nil {
+		hub = NewFarcasterHub()
+		defer hub.Close()
+	}
+	if since > 0 {
+		count = 0
+	}
+	pager := hub.MentionsPager(fid, pageSizeFor(count))
+	messages := make([]*pb.Message, 0)
+	var err error
+	for !pager.Done() && (count == 0 || uint32(len(messages)) < count) {
+		var page []*pb.Message
+		if page, err = pager.Next(); err != nil {
+			break
+		}
+		for _, m := range page {
+			// Pages are sorted newest first.
+			if m.Data.Timestamp < since || (count > 0 && uint32(len(messages)) >= count) {
+				pager.Stop()
+				break
+			}
+			if m.Data.Timestamp == since && seen[Hash(m.Hash)] {
+				continue
+			}
+			messages = append(messages, m)
+		}
+	}
+	grp.appendOrdered(messages)
+	grp.CollectFnames(hub)
+	return grp, err
+}
+
+func (grp *CastGroup) appendOrdered(messages []*pb.Message) {
+	for _, cast := range messages {
+		hash := Hash(cast.Hash)
+		grp.Messages[hash] = &Cast{Message: cast}
+		grp.Ordered = append(grp.Ordered, hash)
+	}
+}
+
+// Newest returns the timestamp of the most recent cast in the group.
+func (grp *CastGroup) Newest() uint32 {
+	var newest uint32
+	for _, c := range grp.Messages {
+		newest = max(newest, c.Message.Data.Timestamp)
+	}
+	return newest
+}
+
+// NewestHashes returns the hashes of the casts posted at Newest(), sorted.
+func (grp *CastGroup) NewestHashes() []Hash {
+	newest := grp.Newest()
+	hashes := make([]Hash, 0)
+	for h, c := range grp.Messages {
+		if c.Message.Data.Timestamp == newest {
+			hashes = append(hashes, h)
+		}
+	}
+	sort.Slice(hashes, func(i, j int) bool {
+		return bytes.Compare(hashes[i][:], hashes[j][:]) < 0
+	})
+	return hashes
+}
+
 /*
 Populates a CastGroup with recent likes from an Fid.
 Head is set to nil.
@@ -164,47 +277,152 @@ func (grp *CastGroup) AppendCast(hub *FarcasterHub, castId *pb.CastId) *CastGrou
 	return grp
 }
 
+/*
+expandReplies adds the tree of replies under hash to the group, breadth-first.
+The replies of each level are fetched concurrently. Expansion stops
+after grp.MaxDepth levels, or when the group holds grp.MaxCasts casts.
+Replies are sorted by timestamp, so the result does not depend on
+the order the hub returns them in.
+*/
 func (grp *CastGroup) expandReplies(hub *FarcasterHub, hash Hash) {
-	replies, err := hub.GetCastReplies(grp.Messages[hash].Message.Data.Fid, grp.Messages[hash].Message.Hash)
-	if err != nil {
-		return
+	level := []Hash{hash}
+	expanded := map[Hash]bool{hash: true}
+	for depth := 1; len(level) > 0; depth++ {
+		if grp.MaxDepth > 0 && depth > grp.MaxDepth {
+			return
+		}
+		replies := make([][]*pb.Message, len(level))
+		parallel(len(level), func(i int) {
+			parent := grp.Messages[level[i]].Message
+			if res, err := hub.GetCastReplies(parent.Data.Fid, parent.Hash); err == nil {
+				replies[i] = res.Messages
+			}
+		})
+		next := make([]Hash, 0)
+		for i, h := range level {
+			sortByTimestamp(replies[i])
+			parent := grp.Messages[h]
+			for _, r := range replies[i] {
+				if expanded[Hash(r.Hash)] {
+					continue
+				}
+				// Parents of the cast FromCast started from are already in the group.
+				if _, ok := grp.Messages[Hash(r.Hash)]; !ok {
+					if grp.MaxCasts > 0 && len(grp.Messages) >= grp.MaxCasts {
+						return
+					}
+					grp.Messages[Hash(r.Hash)] = &Cast{Message: r}
+				}
+				expanded[Hash(r.Hash)] = true
+				parent.Replies = append(parent.Replies, Hash(r.Hash))
+				next = append(next, Hash(r.Hash))
+			}
+		}
+		level = next
 	}
-	for _, r := range replies.Messages {
-		parent := grp.Messages[hash]
-		parent.Replies = append(parent.Replies, Hash(r.Hash))
-		grp.Messages[Hash(r.Hash)] = &Cast{Message: r}
-		grp.expandReplies(hub, Hash(r.Hash))
+}
+
+// sortByTimestamp sorts messages oldest first. Ties are broken by hash.
+func sortByTimestamp(messages []*pb.Message) {
+	sort.SliceStable(messages, func(i, j int) bool {
+		if messages[i].Data.Timestamp != messages[j].Data.Timestamp {
+			return messages[i].Data.Timestamp < messages[j].Data.Timestamp
+		}
+		return bytes.Compare(messages[i].Hash, messages[j].Hash) < 0
+	})
+}
+
+/*
+AuthorThread returns hash, followed by the chain of replies that the
+author of hash posted under it, one per level. This is how
+long texts split by ProcessCastBody are posted.
+If the author replied more than once at the same level, the chain
+follows the earliest reply (the lowest hash on equal timestamps).
+*/
+func (grp *CastGroup) AuthorThread(hash Hash) []Hash {
+	cast, ok := grp.Messages[hash]
+	if !ok {
+		return nil
+	}
+	fid := cast.Message.Data.Fid
+	thread := []Hash{hash}
+	for {
+		next := Hash{}
+		for _, reply := range cast.Replies {
+			r, ok := grp.Messages[reply]
+			if !ok || r.Message.Data.Fid != fid {
+				continue
+			}
+			if next.IsZero() || earlier(r, grp.Messages[next]) {
+				next = reply
+			}
+		}
+		if next.IsZero() {
+			return thread
+		}
+		thread = append(thread, next)
+		cast = grp.Messages[next]
 	}
 }
 
-func (grp *CastGroup) CollectFnames(hub *FarcasterHub) *CastGroup {
-	for _, msg := range grp.Messages {
-		grp.Fnames[msg.Message.Data.Fid], _ = hub.PrxGetUserDataStr(msg.Message.Data.Fid, "USER_DATA_TYPE_USERNAME")
+func earlier(a, b *Cast) bool {
+	if ta, tb := a.Message.Data.Timestamp, b.Message.Data.Timestamp; ta != tb {
+		return ta < tb
+	}
+	return bytes.Compare(a.Message.Hash, b.Message.Hash) < 0
+}
 
-		for _, mention := range msg.Message.GetData().GetCastAddBody().GetMentions() {
-			grp.Fnames[mention], _ = hub.PrxGetUserDataStr(mention, "USER_DATA_TYPE_USERNAME")
+// CollectStats counts the likes, recasts and direct replies of every cast in the group.
+func (grp *CastGroup) CollectStats(hub *FarcasterHub) *CastGroup {
+	casts := make([]*Cast, 0, len(grp.Messages))
+	for _, cast := range grp.Messages {
+		casts = append(casts, cast)
+	}
+	parallel(len(casts), func(i int) {
+		cast := casts[i]
+		castId := &pb.CastId{Fid: cast.Message.Data.Fid, Hash: cast.Message.Hash}
+		stats := &CastStats{}
+		if likes, err := hub.CountReactionsByTarget(castId, "REACTION_TYPE_LIKE"); err == nil {
+			stats.Likes = likes
+		}
+		if recasts, err := hub.CountReactionsByTarget(castId, "REACTION_TYPE_RECAST"); err == nil {
+			stats.Recasts = recasts
 		}
+		if replies, err := hub.GetCastReplies(castId.Fid, castId.Hash); err == nil {
+			stats.Replies = len(replies.Messages)
+		}
+		cast.Stats = stats
+	})
+	return grp
+}
 
-		for _, embed := range msg.Message.GetData().GetCastAddBody().GetEmbeds() {
+// CollectFnames looks up the fnames of the authors, mentions, embedded casts and parents of the casts.
+func (grp *CastGroup) CollectFnames(hub *FarcasterHub) *CastGroup {
+	fids := make([]uint64, 0, len(grp.Messages))
+	for _, msg := range grp.Messages {
+		body := msg.Message.GetData().GetCastAddBody()
+		fids = append(fids, msg.Message.Data.Fid)
+		fids = append(fids, body.GetMentions()...)
+		for _, embed := range body.GetEmbeds() {
 			if cid := embed.GetCastId(); cid != nil {
-				grp.Fnames[cid.Fid], _ = hub.PrxGetUserDataStr(cid.Fid, "USER_DATA_TYPE_USERNAME")
+				fids = append(fids, cid.Fid)
 			}
 		}
-		if msg.Message.GetData().GetCastAddBody().GetParentCastId() != nil {
-			p_cast_fid := msg.Message.GetData().GetCastAddBody().GetParentCastId().Fid
-			p_cast_fname, _ := hub.PrxGetUserDataStr(p_cast_fid, "USER_DATA_TYPE_USERNAME")
-			grp.Fnames[p_cast_fid] = p_cast_fname
+		if parent := body.GetParentCastId(); parent != nil {
+			fids = append(fids, parent.Fid)
 		}
-
 	}
+	collectFnames(hub, grp.Fnames, fids)
 	return grp
 }
 
 func (grp *CastGroup) JsonList(hexHashes bool, realTimestamps bool) ([]byte, error) {
-	groupData := make([]interface{}, len(grp.Messages))
+	hashes := grp.Hashes()
+	groupData := make([]interface{}, len(hashes))
 	var jsonData interface{}
 	idx := 0
-	for _, message := range grp.Messages {
+	for _, hash := range hashes {
+		message := grp.Messages[hash]
 		json_bytes, err := protojson.Marshal(message.Message)
 		if err != nil {
 			return nil, err
@@ -237,11 +455,13 @@ func (grp *CastGroup) JsonThread(hexHashes bool, realTimestamps bool) ([]byte, e
 		Casts   map[string]interface{} `json:"casts"`
 		Replies map[string][]string    `json:"replies"`
 		Fnames  map[uint64]string      `json:"fnames"`
+		Stats   map[string]*CastStats  `json:"stats,omitempty"`
 	}{
 		Head:    grp.Head.String(),
 		Casts:   make(map[string]interface{}),
 		Replies: make(map[string][]string),
 		Fnames:  grp.Fnames,
+		Stats:   make(map[string]*CastStats),
 	}
 
 	for hash, message := range grp.Messages {
@@ -262,6 +482,9 @@ func (grp *CastGroup) JsonThread(hexHashes bool, realTimestamps bool) ([]byte, e
 			replyHashes[i] = replyHash.String()
 		}
 		groupData.Replies[hash.String()] = replyHashes
+		if message.Stats != nil {
+			groupData.Stats[hash.String()] = message.Stats
+		}
 	}
 	updatedJsonBytes, err := json.MarshalIndent(groupData, "", "  ")
 	if err != nil {
@@ -272,7 +495,8 @@ func (grp *CastGroup) JsonThread(hexHashes bool, realTimestamps bool) ([]byte, e
 
 func (grp *CastGroup) Links() []string {
 	links := []string{}
-	for _, message := range grp.Messages {
+	for _, hash := range grp.Hashes() {
+		message := grp.Messages[hash]
 		if embeds := message.Message.Data.GetCastAddBody().GetEmbeds(); len(embeds) > 0 {
 			for _, e := range embeds {
 				if e.GetUrl() != "" {
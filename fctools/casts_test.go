@@ -1,15 +1,17 @@
 package fctools
 
 import (
+	"crypto/ed25519"
 	"encoding/hex"
+	"encoding/json"
 	"testing"
 
 	pb "github.com/vrypan/fargo/farcaster"
 )
 
 func Test_JsonThread(t *testing.T) {
-	hash, _ := hex.DecodeString("13d491c6583c9bac177e6f8d76791e7326def624")
-	castId := pb.CastId{Fid: 280, Hash: hash}
+	testHub(t)
+	castId := pb.CastId{Fid: 280, Hash: threadHead.Hash}
 	s, err := NewCastGroup().FromCast(nil, &castId, true).JsonThread(false, false)
 	if err != nil {
 		t.Fatal(err)
@@ -17,21 +19,47 @@ func Test_JsonThread(t *testing.T) {
 	t.Log("\n" + string(s))
 }
 
+func Test_JsonThreadStats(t *testing.T) {
+	testHub(t)
+	hub := NewFarcasterHub()
+	defer hub.Close()
+	castId := pb.CastId{Fid: 280, Hash: threadHead.Hash}
+	s, err := NewCastGroup().FromCast(hub, &castId, true).CollectStats(hub).JsonThread(false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var thread struct {
+		Head  string
+		Stats map[string]CastStats
+	}
+	if err := json.Unmarshal(s, &thread); err != nil {
+		t.Fatal(err)
+	}
+	if len(thread.Stats) != len(threadCasts) {
+		t.Fatalf("Expected stats for %d casts, got %d", len(threadCasts), len(thread.Stats))
+	}
+	if stats := thread.Stats[thread.Head]; stats.Replies != 2 || stats.Likes != 0 {
+		t.Fatalf("Unexpected head stats: %+v", stats)
+	}
+}
+
 func Test_ThreadFromCast(t *testing.T) {
+	testHub(t)
 	grp := NewCastGroup()
-	hash, _ := hex.DecodeString("da8b15abd2bde4545ecaf548df0192663746a426")
-	castId := pb.CastId{Fid: 20396, Hash: hash}
+	castId := pb.CastId{Fid: threadReply.Data.Fid, Hash: threadReply.Hash}
 	grp.FromCast(nil, &castId, true)
-	// t.Log(grp)
-	if hex.EncodeToString(grp.Head[:]) != "9d423ea8741c753eb17832c377e14027e6ea4fbd" {
-		t.Fatalf("msg.Head is not 0x9d423ea8741c753eb17832c377e14027e6ea4fbd")
+	if grp.Head != Hash(threadHead.Hash) {
+		t.Fatalf("msg.Head is not 0x%s", hex.EncodeToString(threadHead.Hash))
+	}
+	if len(grp.Messages) != len(threadCasts) {
+		t.Fatalf("Expected %d casts in thread, got %d", len(threadCasts), len(grp.Messages))
 	}
 }
 
 func Test_ThreadFromCast2(t *testing.T) {
+	testHub(t)
 	grp := NewCastGroup()
-	hash, _ := hex.DecodeString("13d491c6583c9bac177e6f8d76791e7326def624")
-	castId := pb.CastId{Fid: 280, Hash: hash}
+	castId := pb.CastId{Fid: 280, Hash: threadHead.Hash}
 	grp.FromCast(nil, &castId, true)
 	if len(grp.Messages) < 10 {
 		t.Fatalf("Only %d casts in thread?", len(grp.Messages))
@@ -39,17 +67,47 @@ func Test_ThreadFromCast2(t *testing.T) {
 	t.Logf("Total messages in thread: %d", len(grp.Messages))
 }
 
+func Test_ThreadReplies(t *testing.T) {
+	testHub(t)
+	castId := pb.CastId{Fid: 280, Hash: threadHead.Hash}
+	grp := NewCastGroup().FromCast(nil, &castId, true)
+	replies := grp.Messages[grp.Head].Replies
+	if len(replies) != 2 || replies[0] != Hash(threadCasts[1].Hash) || replies[1] != Hash(threadCasts[2].Hash) {
+		t.Fatalf("Replies are not ordered by timestamp: %v", replies)
+	}
+	for fid, fname := range fixtureUsers {
+		if grp.Fnames[fid] != fname {
+			t.Errorf("Expected fname %s for fid %d, got %q", fname, fid, grp.Fnames[fid])
+		}
+	}
+}
+
+func Test_ThreadLimits(t *testing.T) {
+	testHub(t)
+	castId := pb.CastId{Fid: 280, Hash: threadHead.Hash}
+	grp := NewCastGroup().WithLimits(2, 0).FromCast(nil, &castId, true)
+	// The head, its 2 replies and the reply to alice.
+	if len(grp.Messages) != 4 {
+		t.Fatalf("Expected 4 casts with max depth 2, got %d", len(grp.Messages))
+	}
+	grp = NewCastGroup().WithLimits(0, 5).FromCast(nil, &castId, true)
+	if len(grp.Messages) != 5 {
+		t.Fatalf("Expected 5 casts with max casts 5, got %d", len(grp.Messages))
+	}
+}
+
 func Test_ThreadFromCast_No_Expand(t *testing.T) {
-	hash, _ := hex.DecodeString("13d491c6583c9bac177e6f8d76791e7326def624")
-	castId := pb.CastId{Fid: 280, Hash: hash}
+	testHub(t)
+	castId := pb.CastId{Fid: threadReply.Data.Fid, Hash: threadReply.Hash}
 	grp := NewCastGroup().FromCast(nil, &castId, false)
-	if len(grp.Messages) > 1 {
+	if len(grp.Messages) != 1 {
 		t.Fatalf("There are %d messages in group. Expected: 1.", len(grp.Messages))
 	}
 	t.Logf("Total messages in thread: %d", len(grp.Messages))
 }
 
 func Test_Json(t *testing.T) {
+	testHub(t)
 	grp := NewCastGroup().FromFid(nil, 280, 20)
 	if len(grp.Messages) != 20 {
 		t.Fatalf("Expected 20 messages, got %v\n", len(grp.Messages))
@@ -62,8 +120,83 @@ func Test_Json(t *testing.T) {
 }
 
 func Test_Links(t *testing.T) {
+	testHub(t)
 	grp := NewCastGroup().FromFid(nil, 280, 100)
 
 	links := grp.Links()
+	if len(links) != 25 {
+		t.Fatalf("Expected 25 links, got %d", len(links))
+	}
 	t.Log(links)
 }
+
+func Test_AuthorThread(t *testing.T) {
+	grp := NewCastGroup()
+	add := func(b byte, fid uint64, replies ...byte) Hash {
+		h := Hash{b}
+		data := &pb.MessageData{Fid: fid, Timestamp: uint32(100 - b)}
+		c := &Cast{Message: &pb.Message{Hash: h.Bytes(), Data: data}}
+		for _, r := range replies {
+			c.Replies = append(c.Replies, Hash{r})
+		}
+		grp.Messages[h] = c
+		return h
+	}
+	head := add(1, 280, 2, 3)
+	add(2, 3)
+	add(3, 280, 4)
+	add(4, 280)
+
+	thread := grp.AuthorThread(head)
+	if len(thread) != 3 || thread[1] != (Hash{3}) || thread[2] != (Hash{4}) {
+		t.Fatalf("Unexpected thread: %v", thread)
+	}
+
+	// 280 replied twice to 4: the chain follows the earliest reply, 6.
+	grp.Messages[Hash{4}].Replies = []Hash{{5}, {6}}
+	add(5, 280)
+	add(6, 280, 7)
+	add(7, 280)
+	thread = grp.AuthorThread(head)
+	if len(thread) != 5 || thread[3] != (Hash{6}) || thread[4] != (Hash{7}) {
+		t.Fatalf("Unexpected thread: %v", thread)
+	}
+}
+
+func Test_FromMentions(t *testing.T) {
+	hub := testHub(t)
+	mention := func(text string, timestamp uint32) *pb.Message {
+		data := &pb.MessageData{
+			Type:      pb.MessageType_MESSAGE_TYPE_CAST_ADD,
+			Fid:       20396,
+			Timestamp: timestamp,
+			Network:   pb.FarcasterNetwork_FARCASTER_NETWORK_MAINNET,
+			Body: &pb.MessageData_CastAddBody{CastAddBody: &pb.CastAddBody{
+				Text: text, Mentions: []uint64{3}, MentionsPositions: []uint32{0},
+			}},
+		}
+		return CreateMessage(data, fixtureKey.Seed(), fixtureKey.Public().(ed25519.PublicKey))
+	}
+	old, a, b := mention(" old", 200_000_000), mention(" a", 200_000_010), mention(" b", 200_000_010)
+	hub.AddMessages(old, a, b)
+
+	grp, err := NewCastGroup().FromMentions(nil, 3, 1, 0, nil)
+	if err != nil || len(grp.Messages) != 1 {
+		t.Fatalf("Expected 1 mention, got %d (%v)", len(grp.Messages), err)
+	}
+
+	// count is ignored with since.
+	grp, err = NewCastGroup().FromMentions(nil, 3, 1, old.Data.Timestamp, map[Hash]bool{Hash(old.Hash): true})
+	if err != nil || len(grp.Messages) != 2 {
+		t.Fatalf("Expected 2 new mentions, got %d (%v)", len(grp.Messages), err)
+	}
+	if grp.Newest() != a.Data.Timestamp || len(grp.NewestHashes()) != 2 {
+		t.Fatalf("Unexpected newest mentions: %d %v", grp.Newest(), grp.NewestHashes())
+	}
+
+	// Mentions at since are only skipped if they were seen.
+	grp, _ = NewCastGroup().FromMentions(nil, 3, 0, a.Data.Timestamp, map[Hash]bool{Hash(a.Hash): true})
+	if len(grp.Messages) != 1 || grp.Messages[Hash(b.Hash)] == nil {
+		t.Fatalf("Expected only the unseen mention, got %d", len(grp.Messages))
+	}
+}
@@ -0,0 +1,137 @@
+package fctools
+
+/*
+EIP-712 verification claims, used to prove that an Ethereum address
+belongs to an fid (VERIFICATION_ADD_ETH_ADDRESS messages).
+The hub recomputes the claim from the message and checks that
+claim_signature was produced by the verified address.
+*/
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/decred/dcrd/dcrec/secp256k1/v4/ecdsa"
+	pb "github.com/vrypan/fargo/farcaster"
+	"golang.org/x/crypto/sha3"
+)
+
+var ERR_CLAIM_SIGNER_MISMATCH = errors.New("Claim signature was not produced by the verified address")
+
+const (
+	eip712DomainName    = "Farcaster Verify Ethereum Address"
+	eip712DomainVersion = "2.0.0"
+	eip712DomainType    = "EIP712Domain(string name,string version,bytes32 salt)"
+	eip712ClaimType     = "VerificationClaim(uint256 fid,address address,bytes32 blockHash,uint8 network)"
+)
+
+var eip712DomainSalt = []byte{
+	0xf2, 0xd8, 0x57, 0xf4, 0xa3, 0xed, 0xcb, 0x9b, 0x78, 0xb4, 0xd5, 0x03, 0xbf, 0xe7, 0x33, 0xdb,
+	0x1e, 0x3f, 0x6c, 0xdc, 0x2b, 0x79, 0x71, 0xee, 0x73, 0x96, 0x26, 0xc9, 0x7e, 0x86, 0xa5, 0x58,
+}
+
+func keccak256(data ...[]byte) []byte {
+	hasher := sha3.NewLegacyKeccak256()
+	for _, b := range data {
+		hasher.Write(b)
+	}
+	return hasher.Sum(nil)
+}
+
+// word left-pads b to 32 bytes, the size of an ABI-encoded value.
+func word(b []byte) []byte {
+	w := make([]byte, 32)
+	copy(w[32-len(b):], b)
+	return w
+}
+
+/*
+VerificationClaimHash returns the EIP-712 hash of the claim that
+address belongs to fid. blockHash is the hash of a recent
+Ethereum block.
+*/
+func VerificationClaimHash(fid uint64, address []byte, blockHash []byte, network pb.FarcasterNetwork) []byte {
+	domainSeparator := keccak256(
+		keccak256([]byte(eip712DomainType)),
+		keccak256([]byte(eip712DomainName)),
+		keccak256([]byte(eip712DomainVersion)),
+		eip712DomainSalt,
+	)
+	fidBytes := make([]byte, 8)
+	binary.BigEndian.PutUint64(fidBytes, fid)
+	claim := keccak256(
+		keccak256([]byte(eip712ClaimType)),
+		word(fidBytes),
+		word(address),
+		word(blockHash),
+		word([]byte{byte(network)}),
+	)
+	return eip712Hash(domainSeparator, claim)
+}
+
+// eip712Hash returns the hash that is signed for a struct of a domain.
+func eip712Hash(domainSeparator []byte, structHash []byte) []byte {
+	return keccak256([]byte{0x19, 0x01}, domainSeparator, structHash)
+}
+
+// ethSign signs hash with an Ethereum private key, and returns the r || s || v signature.
+func ethSign(privateKey []byte, hash []byte) []byte {
+	compact := ecdsa.SignCompact(secp256k1.PrivKeyFromBytes(privateKey), hash, false)
+	// SignCompact returns v || r || s.
+	return append(compact[1:], compact[0])
+}
+
+// EthAddress returns the Ethereum address of an Ethereum private key.
+func EthAddress(privateKey []byte) []byte {
+	publicKey := secp256k1.PrivKeyFromBytes(privateKey).PubKey()
+	return ethAddressFromPubKey(publicKey)
+}
+
+func ethAddressFromPubKey(publicKey *secp256k1.PublicKey) []byte {
+	// Uncompressed keys are 0x04 || X || Y, the address is
+	// the last 20 bytes of keccak256(X || Y).
+	return keccak256(publicKey.SerializeUncompressed()[1:])[12:]
+}
+
+/*
+SignVerificationClaim signs the verification claim with an Ethereum
+private key. It returns the verified address and the 65-byte
+r || s || v signature.
+*/
+func SignVerificationClaim(privateKey []byte, fid uint64, blockHash []byte, network pb.FarcasterNetwork) ([]byte, []byte, error) {
+	if len(privateKey) != 32 {
+		return nil, nil, fmt.Errorf("Ethereum private key must be 32 bytes, got %d", len(privateKey))
+	}
+	if len(blockHash) != 32 {
+		return nil, nil, fmt.Errorf("Block hash must be 32 bytes, got %d", len(blockHash))
+	}
+	address := EthAddress(privateKey)
+	signature := ethSign(privateKey, VerificationClaimHash(fid, address, blockHash, network))
+	return address, signature, nil
+}
+
+/*
+VerifyClaimSignature checks that signature is the verification
+claim of fid and address, signed by address.
+*/
+func VerifyClaimSignature(fid uint64, address []byte, blockHash []byte, network pb.FarcasterNetwork, signature []byte) error {
+	if len(signature) != 65 {
+		return fmt.Errorf("Claim signature must be 65 bytes, got %d", len(signature))
+	}
+	v := signature[64]
+	if v < 27 {
+		v += 27
+	}
+	compact := append([]byte{v}, signature[:64]...)
+	publicKey, _, err := ecdsa.RecoverCompact(compact, VerificationClaimHash(fid, address, blockHash, network))
+	if err != nil {
+		return fmt.Errorf("%w: %v", ERR_CLAIM_SIGNER_MISMATCH, err)
+	}
+	if !bytes.Equal(ethAddressFromPubKey(publicKey), address) {
+		return ERR_CLAIM_SIGNER_MISMATCH
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package fctools
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+
+	pb "github.com/vrypan/fargo/farcaster"
+)
+
+func Test_EthAddress(t *testing.T) {
+	privateKey := make([]byte, 32)
+	privateKey[31] = 1
+	if s := EthChecksumAddress(EthAddress(privateKey)); s != "0x7E5F4552091A69125d5DfCb7b8C2659029395Bdf" {
+		t.Fatalf("Unexpected address: %s", s)
+	}
+}
+
+func Test_SignVerificationClaim(t *testing.T) {
+	privateKey, _ := hex.DecodeString("4c0883a69102937d6231471b5dbb6204fe5129617082792ae468d01a3f362318")
+	blockHash := bytes.Repeat([]byte{0xab}, 32)
+	network := pb.FarcasterNetwork_FARCASTER_NETWORK_MAINNET
+
+	address, signature, err := SignVerificationClaim(privateKey, 280, blockHash, network)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(signature) != 65 || (signature[64] != 27 && signature[64] != 28) {
+		t.Fatalf("Unexpected signature: %x", signature)
+	}
+	if err := VerifyClaimSignature(280, address, blockHash, network, signature); err != nil {
+		t.Fatal(err)
+	}
+	if err := VerifyClaimSignature(281, address, blockHash, network, signature); err == nil {
+		t.Fatal("Claim for a different fid was accepted")
+	}
+}
+
+/*
+The "Mail" example of the EIP-712 specification, with the digest
+and signature listed there. It checks the hashing of domains and
+structs, and the r || s || v layout of signatures.
+*/
+func Test_EIP712SpecVector(t *testing.T) {
+	unhex := func(s string) []byte {
+		b, _ := hex.DecodeString(s)
+		return b
+	}
+	domainSeparator := keccak256(
+		keccak256([]byte("EIP712Domain(string name,string version,uint256 chainId,address verifyingContract)")),
+		keccak256([]byte("Ether Mail")),
+		keccak256([]byte("1")),
+		word([]byte{1}),
+		word(unhex("cccccccccccccccccccccccccccccccccccccccc")),
+	)
+	if s := hex.EncodeToString(domainSeparator); s != "f2cee375fa42b42143804025fc449deafd50cc031ca257e0b194a650a912090f" {
+		t.Fatalf("Unexpected domain separator: %s", s)
+	}
+	personType := keccak256([]byte("Person(string name,address wallet)"))
+	person := func(name string, wallet string) []byte {
+		return keccak256(personType, keccak256([]byte(name)), word(unhex(wallet)))
+	}
+	mail := keccak256(
+		keccak256([]byte("Mail(Person from,Person to,string contents)Person(string name,address wallet)")),
+		person("Cow", "cd2a3d9f938e13cd947ec05abc7fe734df8dd826"),
+		person("Bob", "bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb"),
+		keccak256([]byte("Hello, Bob!")),
+	)
+	hash := eip712Hash(domainSeparator, mail)
+	if s := hex.EncodeToString(hash); s != "be609aee343fb3c4b28e1df9e632fca64fcfaede20f02e86244efddf30957bd2" {
+		t.Fatalf("Unexpected hash: %s", s)
+	}
+
+	privateKey := keccak256([]byte("cow"))
+	if s := hex.EncodeToString(EthAddress(privateKey)); s != "cd2a3d9f938e13cd947ec05abc7fe734df8dd826" {
+		t.Fatalf("Unexpected address: %s", s)
+	}
+	expected := "4355c47d63924e8a72e509b65029052eb6c299d53a04e167c5775fd466751c9d" +
+		"07299936d304c153f6443dfa05f40ff007d72911b6f72307f996231605b91562" + "1c"
+	if s := hex.EncodeToString(ethSign(privateKey, hash)); s != expected {
+		t.Fatalf("Unexpected signature: %s", s)
+	}
+}
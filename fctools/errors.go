@@ -0,0 +1,48 @@
+package fctools
+
+import (
+	"errors"
+	"fmt"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+/*
+Errors returned by FarcasterHub methods wrap one of these
+when the hub's status code allows it, so that callers can use
+errors.Is. The gRPC status is still available with status.Code.
+*/
+var (
+	ErrNotFound         = errors.New("Not found")
+	ErrUnavailable      = errors.New("Hub unavailable")
+	ErrTimeout          = errors.New("Hub timeout")
+	ErrInvalidSignature = ERR_INVALID_SIGNATURE
+)
+
+// The message of the hub's (Hubble) validation error for bad signatures.
+const HUB_INVALID_SIGNATURE = "invalid signature"
+
+// wrapError wraps gRPC errors with the matching Err* sentinel.
+func wrapError(err error) error {
+	if err == nil {
+		return nil
+	}
+	var kind error
+	switch status.Code(err) {
+	case codes.NotFound:
+		kind = ErrNotFound
+	case codes.Unavailable:
+		kind = ErrUnavailable
+	case codes.DeadlineExceeded:
+		kind = ErrTimeout
+	case codes.InvalidArgument:
+		if status.Convert(err).Message() == HUB_INVALID_SIGNATURE {
+			kind = ErrInvalidSignature
+		}
+	}
+	if kind == nil {
+		return err
+	}
+	return fmt.Errorf("%w: %w", kind, err)
+}
@@ -0,0 +1,31 @@
+package fctools
+
+import (
+	"errors"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func Test_WrapError(t *testing.T) {
+	tests := []struct {
+		err      error
+		expected error
+	}{
+		{status.Error(codes.NotFound, "no such cast"), ErrNotFound},
+		{status.Error(codes.Unavailable, "connection refused"), ErrUnavailable},
+		{status.Error(codes.DeadlineExceeded, "deadline exceeded"), ErrTimeout},
+		{status.Error(codes.InvalidArgument, HUB_INVALID_SIGNATURE), ErrInvalidSignature},
+	}
+	for _, test := range tests {
+		if err := wrapError(test.err); !errors.Is(err, test.expected) || status.Code(err) != status.Code(test.err) {
+			t.Errorf("%v: expected %v, got %v", test.err, test.expected, err)
+		}
+	}
+	// Other errors that mention signatures are not signature failures.
+	err := wrapError(status.Error(codes.InvalidArgument, "claimSignature must be 65 bytes"))
+	if errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("Unexpected ErrInvalidSignature: %v", err)
+	}
+}
@@ -0,0 +1,95 @@
+package fctools
+
+import (
+	"log"
+	"time"
+
+	pb "github.com/vrypan/fargo/farcaster"
+)
+
+// EventFilter selects the messages Watch passes on.
+// Empty fields match everything.
+type EventFilter struct {
+	Fids         []uint64
+	MessageTypes []pb.MessageType
+	ParentUrl    string
+	Mention      uint64
+}
+
+func (f *EventFilter) Match(message *pb.Message) bool {
+	data := message.GetData()
+	if data == nil {
+		return false
+	}
+	if len(f.Fids) > 0 && !contains(f.Fids, data.Fid) {
+		return false
+	}
+	if len(f.MessageTypes) > 0 && !contains(f.MessageTypes, data.Type) {
+		return false
+	}
+	if f.ParentUrl != "" && data.GetCastAddBody().GetParentUrl() != f.ParentUrl {
+		return false
+	}
+	if f.Mention != 0 && !contains(data.GetCastAddBody().GetMentions(), f.Mention) {
+		return false
+	}
+	return true
+}
+
+func contains[T comparable](list []T, v T) bool {
+	for _, x := range list {
+		if x == v {
+			return true
+		}
+	}
+	return false
+}
+
+const maxWatchBackoff = 30 * time.Second
+
+/*
+Watch subscribes to the hub's merge-message events, starting from
+event fromId (0 means "from now"), and calls handle for every
+message that matches filter.
+
+If the stream fails, Watch reconnects and resumes after the last
+event it has seen. It returns when handle returns false.
+*/
+func (hub FarcasterHub) Watch(fromId uint64, filter *EventFilter, handle func(*pb.HubEvent, *pb.Message) bool) error {
+	lastId := fromId
+	seen := false
+	backoff := time.Second
+	for {
+		req := &pb.SubscribeRequest{EventTypes: []pb.HubEventType{pb.HubEventType_HUB_EVENT_TYPE_MERGE_MESSAGE}}
+		if lastId > 0 {
+			req.FromId = &lastId
+		}
+		stream, err := hub.client.Subscribe(hub.ctx, req)
+		for err == nil {
+			var event *pb.HubEvent
+			event, err = stream.Recv()
+			if err != nil {
+				break
+			}
+			backoff = time.Second
+			// FromId is inclusive, skip the event we have already seen.
+			if seen && event.Id <= lastId {
+				continue
+			}
+			lastId, seen = event.Id, true
+			message := event.GetMergeMessageBody().GetMessage()
+			if message == nil || !filter.Match(message) {
+				continue
+			}
+			if !handle(event, message) {
+				return nil
+			}
+		}
+		if hub.ctx.Err() != nil {
+			return hub.ctx.Err()
+		}
+		log.Printf("Stream error: %v. Reconnecting in %v, from event %d", err, backoff, lastId)
+		time.Sleep(backoff)
+		backoff = min(backoff*2, maxWatchBackoff)
+	}
+}
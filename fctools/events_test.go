@@ -0,0 +1,40 @@
+package fctools
+
+import (
+	"testing"
+
+	pb "github.com/vrypan/fargo/farcaster"
+)
+
+func Test_EventFilter(t *testing.T) {
+	message := &pb.Message{Data: &pb.MessageData{
+		Fid:  280,
+		Type: pb.MessageType_MESSAGE_TYPE_CAST_ADD,
+		Body: &pb.MessageData_CastAddBody{CastAddBody: &pb.CastAddBody{
+			Mentions: []uint64{3},
+			Parent:   &pb.CastAddBody_ParentUrl{ParentUrl: "chain://eip155:1/erc721:0xabc"},
+		}},
+	}}
+	match := []EventFilter{
+		{},
+		{Fids: []uint64{1, 280}},
+		{MessageTypes: []pb.MessageType{pb.MessageType_MESSAGE_TYPE_CAST_ADD}},
+		{ParentUrl: "chain://eip155:1/erc721:0xabc", Mention: 3},
+	}
+	for i, f := range match {
+		if !f.Match(message) {
+			t.Errorf("Filter %d should match", i)
+		}
+	}
+	noMatch := []EventFilter{
+		{Fids: []uint64{1}},
+		{MessageTypes: []pb.MessageType{pb.MessageType_MESSAGE_TYPE_REACTION_ADD}},
+		{ParentUrl: "https://example.com"},
+		{Mention: 280},
+	}
+	for i, f := range noMatch {
+		if f.Match(message) {
+			t.Errorf("Filter %d should not match", i)
+		}
+	}
+}
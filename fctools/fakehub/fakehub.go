@@ -0,0 +1,413 @@
+/*
+Package fakehub is an in-memory Farcaster hub, served over an in-process
+bufconn listener. It is meant for tests:
+
+	hub := fakehub.New()
+	hub.AddMessages(messages...)
+	hub.Start()
+	defer hub.Stop()
+	fctools.SetDialer(hub.Dial)
+
+Messages are served back as they were added; hashes and signatures are
+not checked. Paginated calls honour page_size, page_token and reverse.
+*/
+package fakehub
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"slices"
+	"sort"
+	"strconv"
+	"sync"
+
+	pb "github.com/vrypan/fargo/farcaster"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// Page size used when a request does not set one.
+const DEFAULT_PAGE_SIZE = 100
+
+type Hub struct {
+	pb.UnimplementedHubServiceServer
+
+	mu       sync.Mutex
+	messages []*pb.Message
+	proofs   []*pb.UserNameProof
+	events   []*pb.OnChainEvent
+	limits   map[uint64]*pb.StorageLimitsResponse
+
+	listener *bufconn.Listener
+	server   *grpc.Server
+}
+
+func New() *Hub {
+	return &Hub{limits: make(map[uint64]*pb.StorageLimitsResponse)}
+}
+
+func (h *Hub) AddMessages(messages ...*pb.Message) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.messages = append(h.messages, messages...)
+}
+
+func (h *Hub) AddUsernameProofs(proofs ...*pb.UserNameProof) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.proofs = append(h.proofs, proofs...)
+}
+
+func (h *Hub) AddOnChainEvents(events ...*pb.OnChainEvent) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.events = append(h.events, events...)
+}
+
+func (h *Hub) SetStorageLimits(fid uint64, limits *pb.StorageLimitsResponse) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.limits[fid] = limits
+}
+
+// Messages returns all the messages of the hub, including submitted ones.
+func (h *Hub) Messages() []*pb.Message {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return slices.Clone(h.messages)
+}
+
+// Start serves the hub on a bufconn listener.
+func (h *Hub) Start() {
+	h.listener = bufconn.Listen(1024 * 1024)
+	h.server = grpc.NewServer()
+	pb.RegisterHubServiceServer(h.server, h)
+	go h.server.Serve(h.listener)
+}
+
+func (h *Hub) Stop() {
+	h.server.Stop()
+}
+
+/*
+Dial returns a connection to the hub. addr is ignored, so Dial
+can be passed to fctools.SetDialer.
+*/
+func (h *Hub) Dial(addr string) (*grpc.ClientConn, error) {
+	return grpc.DialContext(context.Background(), "passthrough:///fakehub",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return h.listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+}
+
+// find returns the messages for which match is true.
+func (h *Hub) find(match func(m *pb.Message) bool) []*pb.Message {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	found := make([]*pb.Message, 0)
+	for _, m := range h.messages {
+		if match(m) {
+			found = append(found, m)
+		}
+	}
+	return found
+}
+
+// page returns one page of messages, oldest first unless reverse is set.
+func page(messages []*pb.Message, pageSize *uint32, pageToken []byte, reverse *bool) (*pb.MessagesResponse, error) {
+	sort.SliceStable(messages, func(i, j int) bool {
+		return messages[i].Data.Timestamp < messages[j].Data.Timestamp
+	})
+	if reverse != nil && *reverse {
+		slices.Reverse(messages)
+	}
+	offset := 0
+	if len(pageToken) > 0 {
+		var err error
+		if offset, err = strconv.Atoi(string(pageToken)); err != nil || offset > len(messages) {
+			return nil, status.Error(codes.InvalidArgument, "invalid page token")
+		}
+	}
+	size := DEFAULT_PAGE_SIZE
+	if pageSize != nil && *pageSize > 0 {
+		size = int(*pageSize)
+	}
+	end := min(offset+size, len(messages))
+	res := &pb.MessagesResponse{Messages: messages[offset:end]}
+	if end < len(messages) {
+		res.NextPageToken = []byte(strconv.Itoa(end))
+	}
+	return res, nil
+}
+
+func isType(m *pb.Message, t pb.MessageType) bool {
+	return m.Data != nil && m.Data.Type == t
+}
+
+// Like hubs, REACTION_TYPE_NONE matches all reactions.
+func matchReactionType(m *pb.Message, t *pb.ReactionType) bool {
+	return t == nil || *t == pb.ReactionType_REACTION_TYPE_NONE || m.Data.GetReactionBody().GetType() == *t
+}
+
+func matchLinkType(m *pb.Message, t *string) bool {
+	return t == nil || *t == "" || m.Data.GetLinkBody().GetType() == *t
+}
+
+func (h *Hub) GetInfo(ctx context.Context, req *pb.HubInfoRequest) (*pb.HubInfoResponse, error) {
+	return &pb.HubInfoResponse{Version: "fakehub", Nickname: "fakehub"}, nil
+}
+
+func (h *Hub) SubmitMessage(ctx context.Context, message *pb.Message) (*pb.Message, error) {
+	h.AddMessages(message)
+	return message, nil
+}
+
+func (h *Hub) SubmitBulkMessages(ctx context.Context, req *pb.SubmitBulkMessagesRequest) (*pb.SubmitBulkMessagesResponse, error) {
+	res := &pb.SubmitBulkMessagesResponse{}
+	for _, m := range req.Messages {
+		h.AddMessages(m)
+		res.Messages = append(res.Messages, &pb.BulkMessageResponse{
+			Response: &pb.BulkMessageResponse_Message{Message: m},
+		})
+	}
+	return res, nil
+}
+
+func (h *Hub) ValidateMessage(ctx context.Context, message *pb.Message) (*pb.ValidationResponse, error) {
+	return &pb.ValidationResponse{Valid: true, Message: message}, nil
+}
+
+func (h *Hub) GetCast(ctx context.Context, req *pb.CastId) (*pb.Message, error) {
+	found := h.find(func(m *pb.Message) bool {
+		return isType(m, pb.MessageType_MESSAGE_TYPE_CAST_ADD) && m.Data.Fid == req.Fid && bytes.Equal(m.Hash, req.Hash)
+	})
+	if len(found) == 0 {
+		return nil, status.Error(codes.NotFound, "cast not found")
+	}
+	return found[0], nil
+}
+
+func (h *Hub) GetCastsByFid(ctx context.Context, req *pb.FidRequest) (*pb.MessagesResponse, error) {
+	found := h.find(func(m *pb.Message) bool {
+		return isType(m, pb.MessageType_MESSAGE_TYPE_CAST_ADD) && m.Data.Fid == req.Fid
+	})
+	return page(found, req.PageSize, req.PageToken, req.Reverse)
+}
+
+func (h *Hub) GetCastsByParent(ctx context.Context, req *pb.CastsByParentRequest) (*pb.MessagesResponse, error) {
+	found := h.find(func(m *pb.Message) bool {
+		if !isType(m, pb.MessageType_MESSAGE_TYPE_CAST_ADD) {
+			return false
+		}
+		body := m.Data.GetCastAddBody()
+		if parent := req.GetParentCastId(); parent != nil {
+			return body.GetParentCastId().GetFid() == parent.Fid && bytes.Equal(body.GetParentCastId().GetHash(), parent.Hash)
+		}
+		return body.GetParentUrl() != "" && body.GetParentUrl() == req.GetParentUrl()
+	})
+	return page(found, req.PageSize, req.PageToken, req.Reverse)
+}
+
+func (h *Hub) GetCastsByMention(ctx context.Context, req *pb.FidRequest) (*pb.MessagesResponse, error) {
+	found := h.find(func(m *pb.Message) bool {
+		return isType(m, pb.MessageType_MESSAGE_TYPE_CAST_ADD) && slices.Contains(m.Data.GetCastAddBody().GetMentions(), req.Fid)
+	})
+	return page(found, req.PageSize, req.PageToken, req.Reverse)
+}
+
+func (h *Hub) GetReactionsByFid(ctx context.Context, req *pb.ReactionsByFidRequest) (*pb.MessagesResponse, error) {
+	found := h.find(func(m *pb.Message) bool {
+		return isType(m, pb.MessageType_MESSAGE_TYPE_REACTION_ADD) && m.Data.Fid == req.Fid &&
+			matchReactionType(m, req.ReactionType)
+	})
+	return page(found, req.PageSize, req.PageToken, req.Reverse)
+}
+
+func (h *Hub) GetReactionsByTarget(ctx context.Context, req *pb.ReactionsByTargetRequest) (*pb.MessagesResponse, error) {
+	found := h.find(func(m *pb.Message) bool {
+		if !isType(m, pb.MessageType_MESSAGE_TYPE_REACTION_ADD) {
+			return false
+		}
+		body := m.Data.GetReactionBody()
+		if !matchReactionType(m, req.ReactionType) {
+			return false
+		}
+		if target := req.GetTargetCastId(); target != nil {
+			return body.GetTargetCastId().GetFid() == target.Fid && bytes.Equal(body.GetTargetCastId().GetHash(), target.Hash)
+		}
+		return body.GetTargetUrl() != "" && body.GetTargetUrl() == req.GetTargetUrl()
+	})
+	return page(found, req.PageSize, req.PageToken, req.Reverse)
+}
+
+func (h *Hub) GetReaction(ctx context.Context, req *pb.ReactionRequest) (*pb.Message, error) {
+	found := h.find(func(m *pb.Message) bool {
+		if !isType(m, pb.MessageType_MESSAGE_TYPE_REACTION_ADD) || m.Data.Fid != req.Fid ||
+			m.Data.GetReactionBody().GetType() != req.ReactionType {
+			return false
+		}
+		body := m.Data.GetReactionBody()
+		if target := req.GetTargetCastId(); target != nil {
+			return body.GetTargetCastId().GetFid() == target.Fid && bytes.Equal(body.GetTargetCastId().GetHash(), target.Hash)
+		}
+		return body.GetTargetUrl() != "" && body.GetTargetUrl() == req.GetTargetUrl()
+	})
+	if len(found) == 0 {
+		return nil, status.Error(codes.NotFound, "reaction not found")
+	}
+	return found[0], nil
+}
+
+func (h *Hub) GetLink(ctx context.Context, req *pb.LinkRequest) (*pb.Message, error) {
+	found := h.find(func(m *pb.Message) bool {
+		return isType(m, pb.MessageType_MESSAGE_TYPE_LINK_ADD) && m.Data.Fid == req.Fid &&
+			m.Data.GetLinkBody().GetType() == req.LinkType && m.Data.GetLinkBody().GetTargetFid() == req.GetTargetFid()
+	})
+	if len(found) == 0 {
+		return nil, status.Error(codes.NotFound, "link not found")
+	}
+	return found[0], nil
+}
+
+func (h *Hub) GetLinksByFid(ctx context.Context, req *pb.LinksByFidRequest) (*pb.MessagesResponse, error) {
+	found := h.find(func(m *pb.Message) bool {
+		return isType(m, pb.MessageType_MESSAGE_TYPE_LINK_ADD) && m.Data.Fid == req.Fid &&
+			matchLinkType(m, req.LinkType)
+	})
+	return page(found, req.PageSize, req.PageToken, req.Reverse)
+}
+
+func (h *Hub) GetLinksByTarget(ctx context.Context, req *pb.LinksByTargetRequest) (*pb.MessagesResponse, error) {
+	found := h.find(func(m *pb.Message) bool {
+		return isType(m, pb.MessageType_MESSAGE_TYPE_LINK_ADD) && m.Data.GetLinkBody().GetTargetFid() == req.GetTargetFid() &&
+			matchLinkType(m, req.LinkType)
+	})
+	return page(found, req.PageSize, req.PageToken, req.Reverse)
+}
+
+func (h *Hub) GetUserData(ctx context.Context, req *pb.UserDataRequest) (*pb.Message, error) {
+	found := h.find(func(m *pb.Message) bool {
+		return isType(m, pb.MessageType_MESSAGE_TYPE_USER_DATA_ADD) && m.Data.Fid == req.Fid &&
+			m.Data.GetUserDataBody().GetType() == req.UserDataType
+	})
+	if len(found) == 0 {
+		return nil, status.Error(codes.NotFound, "user data not found")
+	}
+	// The latest message wins.
+	latest := found[0]
+	for _, m := range found {
+		if m.Data.Timestamp >= latest.Data.Timestamp {
+			latest = m
+		}
+	}
+	return latest, nil
+}
+
+func (h *Hub) GetUserDataByFid(ctx context.Context, req *pb.FidRequest) (*pb.MessagesResponse, error) {
+	found := h.find(func(m *pb.Message) bool {
+		return isType(m, pb.MessageType_MESSAGE_TYPE_USER_DATA_ADD) && m.Data.Fid == req.Fid
+	})
+	return page(found, req.PageSize, req.PageToken, req.Reverse)
+}
+
+func (h *Hub) GetVerificationsByFid(ctx context.Context, req *pb.FidRequest) (*pb.MessagesResponse, error) {
+	found := h.find(func(m *pb.Message) bool {
+		return isType(m, pb.MessageType_MESSAGE_TYPE_VERIFICATION_ADD_ETH_ADDRESS) && m.Data.Fid == req.Fid
+	})
+	return page(found, req.PageSize, req.PageToken, req.Reverse)
+}
+
+func (h *Hub) GetUsernameProof(ctx context.Context, req *pb.UsernameProofRequest) (*pb.UserNameProof, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, p := range h.proofs {
+		if bytes.Equal(p.Name, req.Name) {
+			return p, nil
+		}
+	}
+	return nil, status.Error(codes.NotFound, "username proof not found")
+}
+
+func (h *Hub) GetUserNameProofsByFid(ctx context.Context, req *pb.FidRequest) (*pb.UsernameProofsResponse, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	res := &pb.UsernameProofsResponse{}
+	for _, p := range h.proofs {
+		if p.Fid == req.Fid {
+			res.Proofs = append(res.Proofs, p)
+		}
+	}
+	return res, nil
+}
+
+// findEvents returns the on-chain events for which match is true.
+func (h *Hub) findEvents(match func(e *pb.OnChainEvent) bool) []*pb.OnChainEvent {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	found := make([]*pb.OnChainEvent, 0)
+	for _, e := range h.events {
+		if match(e) {
+			found = append(found, e)
+		}
+	}
+	return found
+}
+
+func (h *Hub) GetOnChainEvents(ctx context.Context, req *pb.OnChainEventRequest) (*pb.OnChainEventResponse, error) {
+	return &pb.OnChainEventResponse{Events: h.findEvents(func(e *pb.OnChainEvent) bool {
+		return e.Fid == req.Fid && e.Type == req.EventType
+	})}, nil
+}
+
+func (h *Hub) GetOnChainSigner(ctx context.Context, req *pb.SignerRequest) (*pb.OnChainEvent, error) {
+	found := h.findEvents(func(e *pb.OnChainEvent) bool {
+		return e.Fid == req.Fid && e.Type == pb.OnChainEventType_EVENT_TYPE_SIGNER &&
+			bytes.Equal(e.GetSignerEventBody().GetKey(), req.Signer)
+	})
+	if len(found) == 0 {
+		return nil, status.Error(codes.NotFound, "signer not found")
+	}
+	return found[len(found)-1], nil
+}
+
+func (h *Hub) GetOnChainSignersByFid(ctx context.Context, req *pb.FidRequest) (*pb.OnChainEventResponse, error) {
+	return &pb.OnChainEventResponse{Events: h.findEvents(func(e *pb.OnChainEvent) bool {
+		return e.Fid == req.Fid && e.Type == pb.OnChainEventType_EVENT_TYPE_SIGNER &&
+			e.GetSignerEventBody().GetEventType() == pb.SignerEventType_SIGNER_EVENT_TYPE_ADD
+	})}, nil
+}
+
+func (h *Hub) GetIdRegistryOnChainEvent(ctx context.Context, req *pb.FidRequest) (*pb.OnChainEvent, error) {
+	found := h.findEvents(func(e *pb.OnChainEvent) bool {
+		return e.Fid == req.Fid && e.Type == pb.OnChainEventType_EVENT_TYPE_ID_REGISTER
+	})
+	if len(found) == 0 {
+		return nil, status.Error(codes.NotFound, "fid not registered")
+	}
+	return found[len(found)-1], nil
+}
+
+func (h *Hub) GetIdRegistryOnChainEventByAddress(ctx context.Context, req *pb.IdRegistryEventByAddressRequest) (*pb.OnChainEvent, error) {
+	found := h.findEvents(func(e *pb.OnChainEvent) bool {
+		return e.Type == pb.OnChainEventType_EVENT_TYPE_ID_REGISTER && bytes.Equal(e.GetIdRegisterEventBody().GetTo(), req.Address)
+	})
+	if len(found) == 0 {
+		return nil, status.Error(codes.NotFound, "address has no fid")
+	}
+	return found[len(found)-1], nil
+}
+
+func (h *Hub) GetCurrentStorageLimitsByFid(ctx context.Context, req *pb.FidRequest) (*pb.StorageLimitsResponse, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if limits, ok := h.limits[req.Fid]; ok {
+		return limits, nil
+	}
+	return &pb.StorageLimitsResponse{}, nil
+}
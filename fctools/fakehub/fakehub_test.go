@@ -0,0 +1,65 @@
+package fakehub
+
+import (
+	"context"
+	"testing"
+
+	pb "github.com/vrypan/fargo/farcaster"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func cast(fid uint64, timestamp uint32) *pb.Message {
+	return &pb.Message{
+		Hash: []byte{byte(timestamp)},
+		Data: &pb.MessageData{Type: pb.MessageType_MESSAGE_TYPE_CAST_ADD, Fid: fid, Timestamp: timestamp},
+	}
+}
+
+func Test_Paging(t *testing.T) {
+	hub := New()
+	for i := uint32(1); i <= 5; i++ {
+		hub.AddMessages(cast(280, i))
+	}
+	hub.AddMessages(cast(3, 10))
+	hub.Start()
+	defer hub.Stop()
+	conn, err := hub.Dial("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	client := pb.NewHubServiceClient(conn)
+
+	pageSize := uint32(2)
+	reverse := true
+	timestamps := make([]uint32, 0)
+	var pageToken []byte
+	for {
+		res, err := client.GetCastsByFid(context.Background(),
+			&pb.FidRequest{Fid: 280, PageSize: &pageSize, PageToken: pageToken, Reverse: &reverse})
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, m := range res.Messages {
+			timestamps = append(timestamps, m.Data.Timestamp)
+		}
+		if pageToken = res.NextPageToken; len(pageToken) == 0 {
+			break
+		}
+	}
+	expected := []uint32{5, 4, 3, 2, 1}
+	if len(timestamps) != len(expected) {
+		t.Fatalf("Expected %v, got %v", expected, timestamps)
+	}
+	for i := range expected {
+		if timestamps[i] != expected[i] {
+			t.Fatalf("Expected %v, got %v", expected, timestamps)
+		}
+	}
+
+	_, err = client.GetCast(context.Background(), &pb.CastId{Fid: 3, Hash: []byte{1}})
+	if status.Code(err) != codes.NotFound {
+		t.Fatalf("Expected NotFound, got %v", err)
+	}
+}
@@ -0,0 +1,136 @@
+package fctools
+
+import (
+	"crypto/ed25519"
+	"fmt"
+	"testing"
+
+	pb "github.com/vrypan/fargo/farcaster"
+	"github.com/vrypan/fargo/fctools/fakehub"
+)
+
+/*
+Fixtures served by testHub:
+  - users 280 (vrypan), 20396 (alice) and 3 (dwr), with user data and fnames
+  - 25 casts by 280, each with an embedded link
+  - a thread started by 280 (threadHead), with nested replies
+  - 12 likes by 280
+*/
+
+var fixtureKey = ed25519.NewKeyFromSeed(make([]byte, ed25519.SeedSize))
+
+var fixtureUsers = map[uint64]string{280: "vrypan", 20396: "alice", 3: "dwr"}
+
+// Farcaster timestamp of the first fixture message.
+const fixtureTime uint32 = 100_000_000
+
+var fixtureClock = fixtureTime
+
+func fixtureMessage(fid uint64, t pb.MessageType, body func(d *pb.MessageData)) *pb.Message {
+	fixtureClock++
+	data := &pb.MessageData{
+		Type:      t,
+		Fid:       fid,
+		Timestamp: fixtureClock,
+		Network:   pb.FarcasterNetwork_FARCASTER_NETWORK_MAINNET,
+	}
+	body(data)
+	return CreateMessage(data, fixtureKey.Seed(), fixtureKey.Public().(ed25519.PublicKey))
+}
+
+func fixtureCast(fid uint64, text string, parent *pb.Message) *pb.Message {
+	return fixtureMessage(fid, pb.MessageType_MESSAGE_TYPE_CAST_ADD, func(d *pb.MessageData) {
+		body := &pb.CastAddBody{Text: text}
+		if parent != nil {
+			body.Parent = &pb.CastAddBody_ParentCastId{
+				ParentCastId: &pb.CastId{Fid: parent.Data.Fid, Hash: parent.Hash},
+			}
+		}
+		d.Body = &pb.MessageData_CastAddBody{CastAddBody: body}
+	})
+}
+
+func fixtureUserData(fid uint64, t pb.UserDataType, value string) *pb.Message {
+	return fixtureMessage(fid, pb.MessageType_MESSAGE_TYPE_USER_DATA_ADD, func(d *pb.MessageData) {
+		d.Body = &pb.MessageData_UserDataBody{UserDataBody: &pb.UserDataBody{Type: t, Value: value}}
+	})
+}
+
+func fixtureLike(fid uint64, target *pb.Message) *pb.Message {
+	return fixtureMessage(fid, pb.MessageType_MESSAGE_TYPE_REACTION_ADD, func(d *pb.MessageData) {
+		d.Body = &pb.MessageData_ReactionBody{ReactionBody: &pb.ReactionBody{
+			Type:   pb.ReactionType_REACTION_TYPE_LIKE,
+			Target: &pb.ReactionBody_TargetCastId{TargetCastId: &pb.CastId{Fid: target.Data.Fid, Hash: target.Hash}},
+		}}
+	})
+}
+
+// The fixture thread: its head, a reply deep in the tree, and all its casts.
+var threadHead, threadReply *pb.Message
+var threadCasts []*pb.Message
+
+func fixtures() ([]*pb.Message, []*pb.UserNameProof) {
+	fixtureClock = fixtureTime
+	messages := make([]*pb.Message, 0)
+	proofs := make([]*pb.UserNameProof, 0)
+	for _, fid := range []uint64{280, 20396, 3} {
+		fname := fixtureUsers[fid]
+		messages = append(messages,
+			fixtureUserData(fid, pb.UserDataType_USER_DATA_TYPE_USERNAME, fname),
+			fixtureUserData(fid, pb.UserDataType_USER_DATA_TYPE_DISPLAY, "Display "+fname),
+			fixtureUserData(fid, pb.UserDataType_USER_DATA_TYPE_BIO, "Bio of "+fname),
+		)
+		proofs = append(proofs, &pb.UserNameProof{
+			Timestamp: uint64(fixtureClock),
+			Name:      []byte(fname),
+			Fid:       fid,
+			Type:      pb.UserNameType_USERNAME_TYPE_FNAME,
+		})
+	}
+
+	for i := 0; i < 25; i++ {
+		cast := fixtureMessage(280, pb.MessageType_MESSAGE_TYPE_CAST_ADD, func(d *pb.MessageData) {
+			d.Body = &pb.MessageData_CastAddBody{CastAddBody: &pb.CastAddBody{
+				Text:   "cast with a link",
+				Embeds: []*pb.Embed{{Embed: &pb.Embed_Url{Url: fmt.Sprintf("https://example.com/%d", i)}}},
+			}}
+		})
+		messages = append(messages, cast)
+	}
+
+	// 280 starts a thread; alice and dwr reply, and 280 replies to the replies.
+	threadHead = fixtureCast(280, "thread head", nil)
+	threadCasts = []*pb.Message{threadHead}
+	parent := threadHead
+	for i := 0; i < 4; i++ {
+		a := fixtureCast(20396, "reply by alice", parent)
+		b := fixtureCast(3, "reply by dwr", parent)
+		c := fixtureCast(280, "reply to alice", a)
+		threadCasts = append(threadCasts, a, b, c)
+		parent = c
+	}
+	threadReply = parent
+	messages = append(messages, threadCasts...)
+
+	casts := messages[len(messages)-len(threadCasts)-25:]
+	for i := 0; i < 12; i++ {
+		messages = append(messages, fixtureLike(280, casts[i]))
+	}
+	return messages, proofs
+}
+
+// testHub points NewFarcasterHub to a fake hub that serves the fixtures.
+func testHub(t *testing.T) *fakehub.Hub {
+	t.Helper()
+	messages, proofs := fixtures()
+	hub := fakehub.New()
+	hub.AddMessages(messages...)
+	hub.AddUsernameProofs(proofs...)
+	hub.Start()
+	SetDialer(hub.Dial)
+	t.Cleanup(func() {
+		SetDialer(nil)
+		hub.Stop()
+	})
+	return hub
+}
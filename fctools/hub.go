@@ -6,64 +6,102 @@ import (
 	"log"
 	"strconv"
 
-	//"time"
 	"crypto/ed25519"
 	"encoding/hex"
 	"encoding/json"
+	"time"
 
 	"github.com/vrypan/fargo/config"
 	pb "github.com/vrypan/fargo/farcaster"
 	db "github.com/vrypan/fargo/localdb"
 	"github.com/zeebo/blake3"
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials"
-	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/protobuf/proto"
 )
 
 const FARCASTER_EPOCH int64 = 1609459200
 
 type FarcasterHub struct {
-	hubAddr    string
-	conn       *grpc.ClientConn
+	pool       *hubPool
 	client     pb.HubServiceClient
 	ctx        context.Context
 	ctx_cancel context.CancelFunc
 }
 
-func NewFarcasterHub() *FarcasterHub {
-	config.Load()
-	hubAddr := config.GetString("hub.host") + ":" + config.GetString("hub.port")
-	cred := insecure.NewCredentials()
+var dialer func(addr string) (*grpc.ClientConn, error)
 
-	if config.GetBool("hub.ssl") {
-		cred = credentials.NewClientTLSFromCert(nil, "")
-	}
+/*
+SetDialer makes NewFarcasterHub connect to hubs with dial,
+for example to a fakehub.Hub. nil restores the default. Used by tests.
+*/
+func SetDialer(dial func(addr string) (*grpc.ClientConn, error)) {
+	dialer = dial
+}
 
-	conn, err := grpc.DialContext(context.Background(), hubAddr, grpc.WithTransportCredentials(cred))
-	if err != nil {
-		log.Fatalf("Did not connect: %v", err)
+/*
+NewFarcasterHub connects to the hubs in hub.endpoints (or hub.host:hub.port).
+With more than one hub, hubs are health-checked first, unless
+hub.healthcheck is false. See auth.go for TLS and API key settings.
+
+Connection errors are not fatal: they are returned (wrapping
+ErrUnavailable) by the calls that need the hub.
+Calls time out after hub.timeout seconds, and are retried
+hub.retries times.
+*/
+func NewFarcasterHub() *FarcasterHub {
+	config.Load()
+	dial := dialer
+	if dial == nil {
+		opts, optsErr := dialOptions()
+		dial = func(addr string) (*grpc.ClientConn, error) {
+			if optsErr != nil {
+				return nil, optsErr
+			}
+			return grpc.DialContext(context.Background(), addr, opts...)
+		}
 	}
-	client := pb.NewHubServiceClient(conn)
+	pool := newHubPool(HubEndpoints(), config.GetBool("hub.spread_reads"), dial)
+	pool.timeout = time.Duration(config.GetInt("hub.timeout")) * time.Second
+	pool.retries = config.GetInt("hub.retries")
 	ctx, cancel := context.WithCancel(context.Background())
-	return &FarcasterHub{
-		hubAddr:    hubAddr,
-		conn:       conn,
-		client:     client,
+	hub := &FarcasterHub{
+		pool:       pool,
+		client:     pb.NewHubServiceClient(pool),
 		ctx:        ctx,
 		ctx_cancel: cancel,
 	}
+	if len(pool.endpoints) > 1 && config.GetBool("hub.healthcheck") {
+		for addr, err := range pool.HealthCheck(ctx) {
+			if err != nil && pool.verbose {
+				log.Printf("Hub %s failed health check: %v", addr, err)
+			}
+		}
+	}
+	return hub
 }
 
 func (h FarcasterHub) Close() {
-	h.conn.Close()
+	h.pool.Close()
 	h.ctx_cancel()
 }
 
+// Endpoint returns the address of the hub currently in use.
+func (hub FarcasterHub) Endpoint() string {
+	return hub.pool.Active()
+}
+
+/*
+WithTimeout returns a copy of hub whose calls time out after d,
+instead of hub.timeout. 0 means no timeout.
+*/
+func (hub FarcasterHub) WithTimeout(d time.Duration) *FarcasterHub {
+	hub.ctx = context.WithValue(hub.ctx, callTimeoutKey{}, d)
+	return &hub
+}
+
 func (hub FarcasterHub) HubInfo() ([]byte, error) {
 	res, err := hub.client.GetInfo(hub.ctx, &pb.HubInfoRequest{DbStats: false})
 	if err != nil {
-		log.Fatalf("could not get HubInfo: %v", err)
 		return nil, err
 	}
 	b, err := json.Marshal(res)
@@ -117,8 +155,13 @@ func (hub FarcasterHub) GetUserDataStr(fid uint64, user_data_type string) (strin
 	s := message.Data.GetUserDataBody().GetValue()
 	return string(s), err
 }
+
+// Prx* methods cache hub responses in localdb.
+// If localdb is not open, they call the hub directly.
 func (hub FarcasterHub) PrxGetUserDataStr(fid uint64, user_data_type string) (string, error) {
-	db.AssertOpen()
+	if !db.IsOpen() {
+		return hub.GetUserDataStr(fid, user_data_type)
+	}
 	val, err := db.Get("GetUserData/" + strconv.FormatUint(fid, 10) + "/" + user_data_type)
 	switch err {
 	case nil:
@@ -137,22 +180,30 @@ func (hub FarcasterHub) PrxGetUserDataStr(fid uint64, user_data_type string) (st
 		}
 		return s, nil
 	default:
-		log.Fatal(err)
 		return "", err
 	}
 }
 
 func (hub FarcasterHub) GetUsernameProofsByFid(fid uint64) ([]string, error) {
-	msg, err := hub.client.GetUserNameProofsByFid(hub.ctx, &pb.FidRequest{Fid: fid})
+	proofs, err := hub.GetUserNameProofs(fid)
 	if err != nil {
 		return nil, err
 	}
-	ret := make([]string, len(msg.Proofs))
-	for i, p := range msg.Proofs {
+	ret := make([]string, len(proofs))
+	for i, p := range proofs {
 		ret[i] = string(p.Name)
 	}
 	return ret, nil
 }
+
+// GetUserNameProofs returns the fname and ENS proofs of fid.
+func (hub FarcasterHub) GetUserNameProofs(fid uint64) ([]*pb.UserNameProof, error) {
+	msg, err := hub.client.GetUserNameProofsByFid(hub.ctx, &pb.FidRequest{Fid: fid})
+	if err != nil {
+		return nil, err
+	}
+	return msg.Proofs, nil
+}
 func (hub FarcasterHub) GetFidByUsername(username string) (uint64, error) {
 	message, err := hub.client.GetUsernameProof(hub.ctx, &pb.UsernameProofRequest{Name: []byte(username)})
 	if err != nil {
@@ -161,7 +212,9 @@ func (hub FarcasterHub) GetFidByUsername(username string) (uint64, error) {
 	return message.Fid, nil
 }
 func (hub FarcasterHub) PrxGetFidByUsername(username string) (uint64, error) {
-	db.AssertOpen()
+	if !db.IsOpen() {
+		return hub.GetFidByUsername(username)
+	}
 	fidBytes, err := db.Get("GetFidByUsername/" + username)
 	switch err {
 	case db.ERR_NOT_FOUND:
@@ -182,30 +235,91 @@ func (hub FarcasterHub) PrxGetFidByUsername(username string) (uint64, error) {
 		}
 		return fid, nil
 	default:
-		log.Fatal(err)
 		return 0, err
 	}
 }
 
-func (hub FarcasterHub) GetCastsByFid(fid uint64, pageSize uint32) ([]*pb.Message, error) {
+func (hub FarcasterHub) CastsByFidPager(fid uint64, pageSize uint32) *MessagePager {
 	reverse := true
-	msg, err := hub.client.GetCastsByFid(hub.ctx, &pb.FidRequest{Fid: fid, Reverse: &reverse, PageSize: &pageSize})
-	if err != nil {
-		return nil, err
-	}
-	return msg.Messages, nil
+	return NewMessagePager(func(pageToken []byte) (*pb.MessagesResponse, error) {
+		return hub.client.GetCastsByFid(hub.ctx,
+			&pb.FidRequest{Fid: fid, Reverse: &reverse, PageSize: &pageSize, PageToken: pageToken},
+		)
+	})
+}
+
+/*
+GetCastsByFid returns the count most recent casts of fid,
+following as many hub pages as needed.
+count == 0 returns all the casts of fid.
+*/
+func (hub FarcasterHub) GetCastsByFid(fid uint64, count uint32) ([]*pb.Message, error) {
+	return hub.CastsByFidPager(fid, pageSizeFor(count)).Collect(count)
+}
+
+func (hub FarcasterHub) MentionsPager(fid uint64, pageSize uint32) *MessagePager {
+	reverse := true
+	return NewMessagePager(func(pageToken []byte) (*pb.MessagesResponse, error) {
+		return hub.client.GetCastsByMention(hub.ctx,
+			&pb.FidRequest{Fid: fid, Reverse: &reverse, PageSize: &pageSize, PageToken: pageToken},
+		)
+	})
 }
 
-func (hub FarcasterHub) GetReactionsByFid(fid uint64, reaction string, pageSize uint32) ([]*pb.Message, error) {
+func (hub FarcasterHub) ReactionsByFidPager(fid uint64, reaction string, pageSize uint32) *MessagePager {
 	reverse := true
 	reactionType := pb.ReactionType(pb.ReactionType_value[reaction])
-	msg, err := hub.client.GetReactionsByFid(hub.ctx,
-		&pb.ReactionsByFidRequest{Fid: fid, ReactionType: &reactionType, Reverse: &reverse, PageSize: &pageSize},
-	)
-	if err != nil {
-		return nil, err
+	return NewMessagePager(func(pageToken []byte) (*pb.MessagesResponse, error) {
+		return hub.client.GetReactionsByFid(hub.ctx,
+			&pb.ReactionsByFidRequest{Fid: fid, ReactionType: &reactionType, Reverse: &reverse, PageSize: &pageSize, PageToken: pageToken},
+		)
+	})
+}
+
+/*
+GetReactionsByFid returns the count most recent reactions of fid.
+count == 0 returns all the reactions of fid.
+*/
+func (hub FarcasterHub) GetReactionsByFid(fid uint64, reaction string, count uint32) ([]*pb.Message, error) {
+	return hub.ReactionsByFidPager(fid, reaction, pageSizeFor(count)).Collect(count)
+}
+
+func (hub FarcasterHub) ReactionsByTargetPager(castId *pb.CastId, reaction string, pageSize uint32) *MessagePager {
+	reverse := true
+	reactionType := pb.ReactionType(pb.ReactionType_value[reaction])
+	return NewMessagePager(func(pageToken []byte) (*pb.MessagesResponse, error) {
+		return hub.client.GetReactionsByTarget(hub.ctx,
+			&pb.ReactionsByTargetRequest{
+				Target:       &pb.ReactionsByTargetRequest_TargetCastId{TargetCastId: castId},
+				ReactionType: &reactionType,
+				Reverse:      &reverse,
+				PageSize:     &pageSize,
+				PageToken:    pageToken,
+			},
+		)
+	})
+}
+
+/*
+GetReactionsByTarget returns the count most recent reactions to a cast.
+count == 0 returns all the reactions.
+*/
+func (hub FarcasterHub) GetReactionsByTarget(castId *pb.CastId, reaction string, count uint32) ([]*pb.Message, error) {
+	return hub.ReactionsByTargetPager(castId, reaction, pageSizeFor(count)).Collect(count)
+}
+
+// CountReactionsByTarget returns the number of reactions to a cast.
+func (hub FarcasterHub) CountReactionsByTarget(castId *pb.CastId, reaction string) (int, error) {
+	pager := hub.ReactionsByTargetPager(castId, reaction, MAX_PAGE_SIZE)
+	total := 0
+	for !pager.Done() {
+		page, err := pager.Next()
+		if err != nil {
+			return total, err
+		}
+		total += len(page)
 	}
-	return msg.Messages, nil
+	return total, nil
 }
 
 func (hub FarcasterHub) GetCast(fid uint64, hash []byte) (*pb.Message, error) {
@@ -213,7 +327,9 @@ func (hub FarcasterHub) GetCast(fid uint64, hash []byte) (*pb.Message, error) {
 }
 
 func (hub FarcasterHub) PrxGetCast(fid uint64, hash []byte) (*pb.Message, error) {
-	db.AssertOpen()
+	if !db.IsOpen() {
+		return hub.GetCast(fid, hash)
+	}
 	dbKey := "GetCast/" + hex.EncodeToString(hash)
 	messageBytes, err := db.Get(dbKey)
 	switch err {
@@ -236,18 +352,124 @@ func (hub FarcasterHub) PrxGetCast(fid uint64, hash []byte) (*pb.Message, error)
 		}
 		return &message, nil
 	default:
-		log.Fatal(err)
 		return nil, err
 	}
 }
 
+func (hub FarcasterHub) CastRepliesPager(fid uint64, hash []byte, pageSize uint32) *MessagePager {
+	return NewMessagePager(func(pageToken []byte) (*pb.MessagesResponse, error) {
+		return hub.client.GetCastsByParent(
+			hub.ctx,
+			&pb.CastsByParentRequest{
+				Parent: &pb.CastsByParentRequest_ParentCastId{
+					ParentCastId: &pb.CastId{Fid: fid, Hash: hash},
+				},
+				PageSize:  &pageSize,
+				PageToken: pageToken,
+			},
+		)
+	})
+}
+
+func (hub FarcasterHub) CastsByParentUrlPager(url string, pageSize uint32) *MessagePager {
+	reverse := true
+	return NewMessagePager(func(pageToken []byte) (*pb.MessagesResponse, error) {
+		return hub.client.GetCastsByParent(
+			hub.ctx,
+			&pb.CastsByParentRequest{
+				Parent:    &pb.CastsByParentRequest_ParentUrl{ParentUrl: url},
+				PageSize:  &pageSize,
+				PageToken: pageToken,
+				Reverse:   &reverse,
+			},
+		)
+	})
+}
+
+/*
+GetCastsByParentUrl returns the count most recent casts replying to url (ex. a channel).
+count == 0 returns all of them.
+*/
+func (hub FarcasterHub) GetCastsByParentUrl(url string, count uint32) ([]*pb.Message, error) {
+	return hub.CastsByParentUrlPager(url, pageSizeFor(count)).Collect(count)
+}
+
+// GetCastReplies returns all the direct replies to a cast.
 func (hub FarcasterHub) GetCastReplies(fid uint64, hash []byte) (*pb.MessagesResponse, error) {
-	return hub.client.GetCastsByParent(
-		hub.ctx,
-		&pb.CastsByParentRequest{
-			Parent: &pb.CastsByParentRequest_ParentCastId{
-				ParentCastId: &pb.CastId{Fid: fid, Hash: hash},
+	messages, err := hub.CastRepliesPager(fid, hash, MAX_PAGE_SIZE).Collect(0)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.MessagesResponse{Messages: messages}, nil
+}
+
+func (hub FarcasterHub) LinksByFidPager(fid uint64, linkType string, pageSize uint32) *MessagePager {
+	reverse := true
+	return NewMessagePager(func(pageToken []byte) (*pb.MessagesResponse, error) {
+		return hub.client.GetLinksByFid(hub.ctx,
+			&pb.LinksByFidRequest{Fid: fid, LinkType: &linkType, Reverse: &reverse, PageSize: &pageSize, PageToken: pageToken},
+		)
+	})
+}
+
+/*
+GetLinksByFid returns the count most recent links (ex. follows) created by fid.
+count == 0 returns all the links of fid.
+*/
+func (hub FarcasterHub) GetLinksByFid(fid uint64, linkType string, count uint32) ([]*pb.Message, error) {
+	return hub.LinksByFidPager(fid, linkType, pageSizeFor(count)).Collect(count)
+}
+
+func (hub FarcasterHub) LinksByTargetPager(fid uint64, linkType string, pageSize uint32) *MessagePager {
+	reverse := true
+	return NewMessagePager(func(pageToken []byte) (*pb.MessagesResponse, error) {
+		return hub.client.GetLinksByTarget(hub.ctx,
+			&pb.LinksByTargetRequest{
+				Target:    &pb.LinksByTargetRequest_TargetFid{TargetFid: fid},
+				LinkType:  &linkType,
+				Reverse:   &reverse,
+				PageSize:  &pageSize,
+				PageToken: pageToken,
 			},
-		},
-	)
+		)
+	})
+}
+
+/*
+GetLinksByTarget returns the count most recent links (ex. follows) pointing to fid.
+count == 0 returns all the links to fid.
+*/
+func (hub FarcasterHub) GetLinksByTarget(fid uint64, linkType string, count uint32) ([]*pb.Message, error) {
+	return hub.LinksByTargetPager(fid, linkType, pageSizeFor(count)).Collect(count)
+}
+
+// ValidateMessage asks the hub to validate message, without merging it.
+func (hub FarcasterHub) ValidateMessage(message *pb.Message) (*pb.ValidationResponse, error) {
+	return hub.client.ValidateMessage(hub.ctx, message)
+}
+
+func (hub FarcasterHub) SubmitBulkMessages(messages []*pb.Message) (*pb.SubmitBulkMessagesResponse, error) {
+	return hub.client.SubmitBulkMessages(hub.ctx, &pb.SubmitBulkMessagesRequest{Messages: messages})
+}
+
+func (hub FarcasterHub) VerificationsByFidPager(fid uint64, pageSize uint32) *MessagePager {
+	reverse := true
+	return NewMessagePager(func(pageToken []byte) (*pb.MessagesResponse, error) {
+		return hub.client.GetVerificationsByFid(hub.ctx,
+			&pb.FidRequest{Fid: fid, Reverse: &reverse, PageSize: &pageSize, PageToken: pageToken},
+		)
+	})
+}
+
+/*
+GetVerificationsByFid returns the count most recent verified addresses of fid.
+count == 0 returns all of them.
+*/
+func (hub FarcasterHub) GetVerificationsByFid(fid uint64, count uint32) ([]*pb.Message, error) {
+	return hub.VerificationsByFidPager(fid, pageSizeFor(count)).Collect(count)
+}
+
+// GetIdRegistryOnChainEventByAddress returns the IdRegistry event of the fid whose custody address is address.
+func (hub FarcasterHub) GetIdRegistryOnChainEventByAddress(address []byte) (*pb.OnChainEvent, error) {
+	return hub.client.GetIdRegistryOnChainEventByAddress(hub.ctx, &pb.IdRegistryEventByAddressRequest{Address: address})
 }
@@ -3,12 +3,11 @@ package fctools
 import (
 	"testing"
 
-	db "github.com/vrypan/fargo/localdb"
+	pb "github.com/vrypan/fargo/farcaster"
 )
 
 func Test_GetFidByUsername_vrypan(t *testing.T) {
-	db.Open()
-	defer db.Close()
+	testHub(t)
 
 	var username string = "vrypan"
 	var expected_fid uint64 = 280
@@ -26,8 +25,7 @@ func Test_GetFidByUsername_vrypan(t *testing.T) {
 }
 
 func Test_likes(t *testing.T) {
-	db.Open()
-	defer db.Close()
+	testHub(t)
 	fid := uint64(280)
 	hub := NewFarcasterHub()
 	defer hub.Close()
@@ -42,3 +40,25 @@ func Test_likes(t *testing.T) {
 		t.Log(m)
 	}
 }
+
+func Test_CountReactionsByTarget(t *testing.T) {
+	testHub(t)
+	hub := NewFarcasterHub()
+	defer hub.Close()
+	likes, err := hub.GetReactionsByFid(280, "REACTION_TYPE_LIKE", 1)
+	if err != nil || len(likes) != 1 {
+		t.Fatalf("Expected a like, got %v", err)
+	}
+	castId := likes[0].Data.GetReactionBody().GetTargetCastId()
+	n, err := hub.CountReactionsByTarget(castId, "REACTION_TYPE_LIKE")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 1 {
+		t.Errorf("Expected 1 like, got %d", n)
+	}
+	castId = &pb.CastId{Fid: threadHead.Data.Fid, Hash: threadHead.Hash}
+	if n, _ := hub.CountReactionsByTarget(castId, "REACTION_TYPE_LIKE"); n != 0 {
+		t.Errorf("Expected no likes, got %d", n)
+	}
+}
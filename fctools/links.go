@@ -0,0 +1,130 @@
+package fctools
+
+import (
+	"encoding/json"
+	"strconv"
+
+	pb "github.com/vrypan/fargo/farcaster"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+const LINK_TYPE_FOLLOW = "follow"
+
+type Link struct {
+	Message *pb.Message
+}
+
+type Links struct {
+	Messages []*Link
+	Fnames   map[uint64]string
+}
+
+func (link *Link) Fid() uint64 {
+	return link.Message.Data.Fid
+}
+
+func (link *Link) TargetFid() uint64 {
+	return link.Message.Data.GetLinkBody().GetTargetFid()
+}
+
+func (link *Link) String() string {
+	user_fid := strconv.FormatUint(link.Fid(), 10)
+	target_fid := strconv.FormatUint(link.TargetFid(), 10)
+	linkType := link.Message.Data.GetLinkBody().GetType()
+	return user_fid + " " + linkType + " --> " + target_fid
+}
+
+func (link *Link) Json(hexHashes bool, realTimestamps bool) ([]byte, error) {
+	var jsonData interface{}
+	jsonBytes, err := protojson.Marshal(link.Message)
+	if err != nil {
+		return nil, err
+	}
+	err = json.Unmarshal(jsonBytes, &jsonData)
+	jsonPretty(jsonData, hexHashes, realTimestamps)
+	if err != nil {
+		return nil, err
+	}
+	updatedJsonBytes, err := json.MarshalIndent(jsonData, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return updatedJsonBytes, nil
+}
+
+func NewLinks() *Links {
+	return &Links{
+		Messages: make([]*Link, 0),
+		Fnames:   make(map[uint64]string),
+	}
+}
+
+/*
+Populates Links with the count most recent links created by an Fid
+(ex. the accounts fid follows).
+count == 0 fetches all the links.
+*/
+func (links *Links) FromFid(hub *FarcasterHub, fid uint64, linkType string, count uint32) *Links {
+	if hub == nil {
+		hub = NewFarcasterHub()
+		defer hub.Close()
+	}
+	if messages, err := hub.GetLinksByFid(fid, linkType, count); err == nil {
+		for _, link := range messages {
+			links.Messages = append(links.Messages, &Link{Message: link})
+		}
+	}
+	return links
+}
+
+/*
+Populates Links with the count most recent links pointing to an Fid
+(ex. the accounts following fid).
+count == 0 fetches all the links.
+*/
+func (links *Links) FromTarget(hub *FarcasterHub, fid uint64, linkType string, count uint32) *Links {
+	if hub == nil {
+		hub = NewFarcasterHub()
+		defer hub.Close()
+	}
+	if messages, err := hub.GetLinksByTarget(fid, linkType, count); err == nil {
+		for _, link := range messages {
+			links.Messages = append(links.Messages, &Link{Message: link})
+		}
+	}
+	return links
+}
+
+// CollectFnames looks up the fnames of both sides of every link.
+func (links *Links) CollectFnames(hub *FarcasterHub) *Links {
+	fids := make([]uint64, 0, 2*len(links.Messages))
+	for _, link := range links.Messages {
+		fids = append(fids, link.Fid(), link.TargetFid())
+	}
+	collectFnames(hub, links.Fnames, fids)
+	return links
+}
+
+func (links *Links) JsonList(hexHashes bool, realTimestamps bool) ([]byte, error) {
+	groupData := make([]interface{}, len(links.Messages))
+	var jsonData interface{}
+	idx := 0
+	for _, message := range links.Messages {
+		json_bytes, err := protojson.Marshal(message.Message)
+		if err != nil {
+			return nil, err
+		}
+		err = json.Unmarshal(json_bytes, &jsonData)
+		jsonPretty(jsonData, hexHashes, realTimestamps)
+		if err != nil {
+			return nil, err
+		}
+		groupData[idx] = jsonData
+		idx++
+	}
+	updatedJsonBytes, err := json.MarshalIndent(groupData, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return updatedJsonBytes, nil
+}
@@ -0,0 +1,28 @@
+package fctools
+
+import (
+	"testing"
+
+	pb "github.com/vrypan/fargo/farcaster"
+)
+
+func Test_LinkString(t *testing.T) {
+	link := &Link{Message: &pb.Message{Data: &pb.MessageData{
+		Fid: 280,
+		Body: &pb.MessageData_LinkBody{LinkBody: &pb.LinkBody{
+			Type:   LINK_TYPE_FOLLOW,
+			Target: &pb.LinkBody_TargetFid{TargetFid: 3},
+		}},
+	}}}
+	if link.TargetFid() != 3 {
+		t.Fatalf("Expected target fid 3, got %d", link.TargetFid())
+	}
+	if s := link.String(); s != "280 follow --> 3" {
+		t.Fatalf("Unexpected link string: %s", s)
+	}
+	links := NewLinks()
+	links.Messages = append(links.Messages, link)
+	if _, err := links.JsonList(true, false); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -1,11 +1,17 @@
 package fctools
 
 import (
+	"bytes"
 	"encoding/base64"
 	"encoding/hex"
 	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
 	"time"
 
+	pb "github.com/vrypan/fargo/farcaster"
 	"google.golang.org/protobuf/encoding/protojson"
 	"google.golang.org/protobuf/proto"
 )
@@ -66,3 +72,96 @@ func Marshal(msg proto.Message, opts MarshalOptions) ([]byte, error) {
 	}
 	return updatedJsonBytes, nil
 }
+
+// restoreHashFields reverses replaceHashFields, so that protojson can parse the result.
+func restoreHashFields(data interface{}) {
+	switch value := data.(type) {
+	case map[string]interface{}:
+		for k, v := range value {
+			s, isString := v.(string)
+			if isString && (k == "hash" || k == "signature" || k == "signer") && strings.HasPrefix(s, "0x") {
+				if bytes, err := hex.DecodeString(s[2:]); err == nil {
+					value[k] = base64.StdEncoding.EncodeToString(bytes)
+				}
+			} else if isString && k == "timestamp" {
+				if t, err := time.Parse(time.RFC3339, s); err == nil {
+					value[k] = t.Unix() - FARCASTER_EPOCH
+				}
+			} else {
+				restoreHashFields(v)
+			}
+		}
+	case []interface{}:
+		for _, v := range value {
+			restoreHashFields(v)
+		}
+	}
+}
+
+/*
+Unmarshal parses a JSON object created by Marshal (or by the
+Json* functions), with or without hex hashes and dates, into msg.
+*/
+func Unmarshal(b []byte, msg proto.Message) error {
+	var jsonData interface{}
+	if err := json.Unmarshal(b, &jsonData); err != nil {
+		return err
+	}
+	return unmarshalValue(jsonData, msg)
+}
+
+func unmarshalValue(jsonData interface{}, msg proto.Message) error {
+	restoreHashFields(jsonData)
+	b, err := json.Marshal(jsonData)
+	if err != nil {
+		return err
+	}
+	return protojson.UnmarshalOptions{DiscardUnknown: true}.Unmarshal(b, msg)
+}
+
+/*
+UnmarshalMessages parses the messages in b. b may contain one or
+more JSON messages (ex. the output of "post cast --prepare"),
+JSON lists of messages, or threads created by JsonThread.
+*/
+func UnmarshalMessages(b []byte) ([]*pb.Message, error) {
+	messages := make([]*pb.Message, 0)
+	decoder := json.NewDecoder(bytes.NewReader(b))
+	for {
+		var jsonData interface{}
+		err := decoder.Decode(&jsonData)
+		if err == io.EOF {
+			return messages, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		var values []interface{}
+		switch value := jsonData.(type) {
+		case []interface{}:
+			values = value
+		case map[string]interface{}:
+			if casts, ok := value["casts"].(map[string]interface{}); ok {
+				keys := make([]string, 0, len(casts))
+				for k := range casts {
+					keys = append(keys, k)
+				}
+				sort.Strings(keys)
+				for _, k := range keys {
+					values = append(values, casts[k])
+				}
+			} else {
+				values = []interface{}{value}
+			}
+		default:
+			return nil, fmt.Errorf("Unexpected JSON value: %v", jsonData)
+		}
+		for _, v := range values {
+			var message pb.Message
+			if err := unmarshalValue(v, &message); err != nil {
+				return nil, err
+			}
+			messages = append(messages, &message)
+		}
+	}
+}
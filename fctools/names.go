@@ -0,0 +1,140 @@
+package fctools
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+	"time"
+
+	pb "github.com/vrypan/fargo/farcaster"
+)
+
+// Values of UsernameProof.OwnerMatch
+const (
+	OWNER_MATCH_CUSTODY      = "custody"
+	OWNER_MATCH_VERIFICATION = "verification"
+	OWNER_MATCH_NONE         = "none"
+)
+
+type UsernameProof struct {
+	Proof *pb.UserNameProof
+	// Set by UsernameProofs.Verify
+	OwnerMatch string
+}
+
+type UsernameProofs struct {
+	Fid    uint64
+	Proofs []*UsernameProof
+}
+
+func (p *UsernameProof) Name() string {
+	return string(p.Proof.Name)
+}
+
+// Type returns "fname" or "ens".
+func (p *UsernameProof) Type() string {
+	switch p.Proof.Type {
+	case pb.UserNameType_USERNAME_TYPE_FNAME:
+		return "fname"
+	case pb.UserNameType_USERNAME_TYPE_ENS_L1:
+		return "ens"
+	default:
+		return strings.ToLower(strings.TrimPrefix(p.Proof.Type.String(), "USERNAME_TYPE_"))
+	}
+}
+
+func (p *UsernameProof) Owner() string {
+	return EthChecksumAddress(p.Proof.Owner)
+}
+
+// Time returns the proof timestamp. Unlike messages, proofs use unix timestamps.
+func (p *UsernameProof) Time() time.Time {
+	return time.Unix(int64(p.Proof.Timestamp), 0).UTC()
+}
+
+/*
+CheckOwner compares the owner of the proof to the custody address and
+the verified addresses of the fid, and returns one of OWNER_MATCH_*.
+fnames are owned by the custody address, so only ENS names can
+match a verified address.
+*/
+func (p *UsernameProof) CheckOwner(custody []byte, verified [][]byte) string {
+	if bytes.Equal(p.Proof.Owner, custody) {
+		return OWNER_MATCH_CUSTODY
+	}
+	if p.Proof.Type == pb.UserNameType_USERNAME_TYPE_ENS_L1 {
+		for _, address := range verified {
+			if bytes.Equal(p.Proof.Owner, address) {
+				return OWNER_MATCH_VERIFICATION
+			}
+		}
+	}
+	return OWNER_MATCH_NONE
+}
+
+func NewUsernameProofs() *UsernameProofs {
+	return &UsernameProofs{Proofs: make([]*UsernameProof, 0)}
+}
+
+func (proofs *UsernameProofs) FromFid(hub *FarcasterHub, fid uint64) *UsernameProofs {
+	if hub == nil {
+		hub = NewFarcasterHub()
+		defer hub.Close()
+	}
+	proofs.Fid = fid
+	if res, err := hub.GetUserNameProofs(fid); err == nil {
+		for _, p := range res {
+			proofs.Proofs = append(proofs.Proofs, &UsernameProof{Proof: p})
+		}
+	}
+	return proofs
+}
+
+/*
+Verify sets OwnerMatch of each proof, using the current custody
+address and the verified Ethereum addresses of the fid.
+*/
+func (proofs *UsernameProofs) Verify(hub *FarcasterHub) error {
+	event, err := hub.GetIdRegistryOnChainEvent(proofs.Fid)
+	if err != nil {
+		return err
+	}
+	custody := event.GetIdRegisterEventBody().GetTo()
+	verifications := NewVerifications().FromFid(hub, proofs.Fid, 0)
+	verified := make([][]byte, 0, len(verifications.Messages))
+	for _, v := range verifications.Messages {
+		if v.body().GetProtocol() == pb.Protocol_PROTOCOL_ETHEREUM {
+			verified = append(verified, v.body().GetAddress())
+		}
+	}
+	for _, p := range proofs.Proofs {
+		p.OwnerMatch = p.CheckOwner(custody, verified)
+	}
+	return nil
+}
+
+func (proofs *UsernameProofs) JsonList() ([]byte, error) {
+	type jsonProof struct {
+		Name       string    `json:"name"`
+		Type       string    `json:"type"`
+		Fid        uint64    `json:"fid"`
+		Owner      string    `json:"owner"`
+		Timestamp  time.Time `json:"timestamp"`
+		Signature  string    `json:"signature"`
+		OwnerMatch string    `json:"ownerMatch,omitempty"`
+	}
+	list := make([]jsonProof, len(proofs.Proofs))
+	for i, p := range proofs.Proofs {
+		list[i] = jsonProof{
+			Name:       p.Name(),
+			Type:       p.Type(),
+			Fid:        p.Proof.Fid,
+			Owner:      p.Owner(),
+			Timestamp:  p.Time(),
+			Signature:  "0x" + hex.EncodeToString(p.Proof.Signature),
+			OwnerMatch: p.OwnerMatch,
+		}
+	}
+	return json.MarshalIndent(list, "", "  ")
+}
@@ -0,0 +1,30 @@
+package fctools
+
+import (
+	"testing"
+
+	pb "github.com/vrypan/fargo/farcaster"
+)
+
+func Test_UsernameProofCheckOwner(t *testing.T) {
+	custody := []byte{1, 1, 1}
+	verified := []byte{2, 2, 2}
+	other := []byte{3, 3, 3}
+
+	tests := []struct {
+		nameType pb.UserNameType
+		owner    []byte
+		match    string
+	}{
+		{pb.UserNameType_USERNAME_TYPE_FNAME, custody, OWNER_MATCH_CUSTODY},
+		{pb.UserNameType_USERNAME_TYPE_FNAME, verified, OWNER_MATCH_NONE},
+		{pb.UserNameType_USERNAME_TYPE_ENS_L1, verified, OWNER_MATCH_VERIFICATION},
+		{pb.UserNameType_USERNAME_TYPE_ENS_L1, other, OWNER_MATCH_NONE},
+	}
+	for _, test := range tests {
+		p := &UsernameProof{Proof: &pb.UserNameProof{Type: test.nameType, Owner: test.owner}}
+		if m := p.CheckOwner(custody, [][]byte{verified}); m != test.match {
+			t.Errorf("%s owned by %x: got %s, expected %s", p.Type(), test.owner, m, test.match)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package fctools
+
+import (
+	pb "github.com/vrypan/fargo/farcaster"
+)
+
+// Hubs will not return more than this many messages per page.
+const MAX_PAGE_SIZE uint32 = 1000
+
+/*
+MessagePager walks a paginated hub response by following
+next_page_token until the hub returns an empty token.
+fetch is called with the page token of the next page
+(nil for the first one).
+*/
+type MessagePager struct {
+	fetch     func(pageToken []byte) (*pb.MessagesResponse, error)
+	pageToken []byte
+	done      bool
+}
+
+func NewMessagePager(fetch func(pageToken []byte) (*pb.MessagesResponse, error)) *MessagePager {
+	return &MessagePager{fetch: fetch}
+}
+
+func (p *MessagePager) Done() bool {
+	return p.done
+}
+
+// Stop makes the pager return no more pages.
+func (p *MessagePager) Stop() {
+	p.done = true
+}
+
+// Next returns the next page of messages. When there are no more
+// pages, it returns nil and Done() becomes true.
+func (p *MessagePager) Next() ([]*pb.Message, error) {
+	if p.done {
+		return nil, nil
+	}
+	res, err := p.fetch(p.pageToken)
+	if err != nil {
+		return nil, err
+	}
+	p.pageToken = res.GetNextPageToken()
+	if len(p.pageToken) == 0 || len(res.Messages) == 0 {
+		p.done = true
+	}
+	return res.Messages, nil
+}
+
+/*
+Collect fetches pages until count messages have been collected
+or there are no more pages. count == 0 means "fetch everything".
+*/
+func (p *MessagePager) Collect(count uint32) ([]*pb.Message, error) {
+	messages := make([]*pb.Message, 0)
+	for !p.done {
+		page, err := p.Next()
+		if err != nil {
+			return messages, err
+		}
+		messages = append(messages, page...)
+		if count > 0 && uint32(len(messages)) >= count {
+			return messages[:count], nil
+		}
+	}
+	return messages, nil
+}
+
+// pageSizeFor returns the page size to request when we want count messages.
+func pageSizeFor(count uint32) uint32 {
+	if count == 0 || count > MAX_PAGE_SIZE {
+		return MAX_PAGE_SIZE
+	}
+	return count
+}
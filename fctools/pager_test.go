@@ -0,0 +1,61 @@
+package fctools
+
+import (
+	"strconv"
+	"testing"
+
+	pb "github.com/vrypan/fargo/farcaster"
+)
+
+// fakePages returns a fetch function that serves total messages,
+// pageSize at a time, using the page number as page token.
+func fakePages(total int, pageSize int, calls *int) func([]byte) (*pb.MessagesResponse, error) {
+	return func(pageToken []byte) (*pb.MessagesResponse, error) {
+		*calls++
+		page := 0
+		if len(pageToken) > 0 {
+			page, _ = strconv.Atoi(string(pageToken))
+		}
+		res := &pb.MessagesResponse{}
+		for i := page * pageSize; i < total && i < (page+1)*pageSize; i++ {
+			res.Messages = append(res.Messages, &pb.Message{Data: &pb.MessageData{Timestamp: uint32(i)}})
+		}
+		if (page+1)*pageSize < total {
+			res.NextPageToken = []byte(strconv.Itoa(page + 1))
+		}
+		return res, nil
+	}
+}
+
+func Test_PagerCollectAll(t *testing.T) {
+	calls := 0
+	messages, err := NewMessagePager(fakePages(25, 10, &calls)).Collect(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(messages) != 25 {
+		t.Fatalf("Expected 25 messages, got %d", len(messages))
+	}
+	if calls != 3 {
+		t.Fatalf("Expected 3 calls, got %d", calls)
+	}
+	for i, m := range messages {
+		if m.Data.Timestamp != uint32(i) {
+			t.Fatalf("Message %d out of order", i)
+		}
+	}
+}
+
+func Test_PagerCollectCount(t *testing.T) {
+	calls := 0
+	messages, err := NewMessagePager(fakePages(25, 10, &calls)).Collect(12)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(messages) != 12 {
+		t.Fatalf("Expected 12 messages, got %d", len(messages))
+	}
+	if calls != 2 {
+		t.Fatalf("Expected 2 calls, got %d", calls)
+	}
+}
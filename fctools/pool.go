@@ -0,0 +1,310 @@
+package fctools
+
+import (
+	"context"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/vrypan/fargo/config"
+	pb "github.com/vrypan/fargo/farcaster"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// How long HealthCheck waits for each hub.
+const HEALTH_CHECK_TIMEOUT = 3 * time.Second
+
+// Delay before the first retry. It doubles on every retry, up to MAX_RETRY_BACKOFF.
+const (
+	RETRY_BACKOFF     = 250 * time.Millisecond
+	MAX_RETRY_BACKOFF = 5 * time.Second
+)
+
+/*
+HubEndpoints returns the configured hubs, "host:port" each.
+hub.endpoints is a list, or a comma or space separated string,
+and takes precedence over hub.host and hub.port.
+*/
+func HubEndpoints() []string {
+	endpoints := make([]string, 0)
+	for _, e := range config.GetStringSlice("hub.endpoints") {
+		for _, addr := range strings.Split(e, ",") {
+			if addr = strings.TrimSpace(addr); addr != "" {
+				endpoints = append(endpoints, addr)
+			}
+		}
+	}
+	if len(endpoints) > 0 {
+		return endpoints
+	}
+	return []string{config.GetString("hub.host") + ":" + config.GetString("hub.port")}
+}
+
+type hubEndpoint struct {
+	addr string
+	conn *grpc.ClientConn
+	// Set if the hub could not be dialed.
+	err error
+}
+
+/*
+hubPool is a grpc.ClientConnInterface that sends each call to one
+of several hubs. Calls go to the active hub. If it is Unavailable or
+the call times out, the call is retried on the next hub, which
+becomes the active one.
+
+If spreadReads is true, read calls are distributed round-robin
+over all the hubs, while writes still go to the active hub.
+
+Each attempt times out after timeout (0 means never). When all the hubs
+fail with a retryable error, the call is retried up to retries times,
+with exponential backoff. Writes that time out are neither retried nor
+sent to another hub, since the hub may have received them.
+*/
+type hubPool struct {
+	endpoints   []*hubEndpoint
+	spreadReads bool
+	verbose     bool
+	timeout     time.Duration
+	retries     int
+
+	mu        sync.Mutex
+	active    int
+	next      int
+	announced bool
+}
+
+// newHubPool creates a pool of the hubs in addrs, connected with dial.
+func newHubPool(addrs []string, spreadReads bool, dial func(addr string) (*grpc.ClientConn, error)) *hubPool {
+	pool := &hubPool{spreadReads: spreadReads, verbose: config.GetBool("verbose")}
+	for _, addr := range addrs {
+		// Dial errors are reported when the hub is used,
+		// so that the other hubs can still be tried.
+		conn, err := dial(addr)
+		pool.endpoints = append(pool.endpoints, &hubEndpoint{addr: addr, conn: conn, err: err})
+	}
+	return pool
+}
+
+func (p *hubPool) Close() {
+	for _, e := range p.endpoints {
+		if e.conn != nil {
+			e.conn.Close()
+		}
+	}
+}
+
+// Active returns the address of the active hub.
+func (p *hubPool) Active() string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.endpoints[p.active].addr
+}
+
+func isWrite(method string) bool {
+	return strings.HasSuffix(method, "/SubmitMessage") || strings.HasSuffix(method, "/SubmitBulkMessages")
+}
+
+// shouldFailover returns true for errors another hub may not have.
+func shouldFailover(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	code := status.Code(err)
+	return code == codes.Unavailable || code == codes.DeadlineExceeded
+}
+
+// shouldRetry returns true for errors that may go away if we try again later.
+func shouldRetry(ctx context.Context, err error) bool {
+	if shouldFailover(ctx, err) {
+		return true
+	}
+	code := status.Code(err)
+	return ctx.Err() == nil && (code == codes.ResourceExhausted || code == codes.Aborted)
+}
+
+type callTimeoutKey struct{}
+
+// callTimeout returns the timeout set with FarcasterHub.WithTimeout, or the pool's default.
+func (p *hubPool) callTimeout(ctx context.Context) time.Duration {
+	if d, ok := ctx.Value(callTimeoutKey{}).(time.Duration); ok {
+		return d
+	}
+	return p.timeout
+}
+
+// sleep waits for d, or until ctx is done.
+func sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+// order returns the endpoint indexes in the order they should be tried.
+func (p *hubPool) order(method string) []int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	first := p.active
+	if p.spreadReads && !isWrite(method) {
+		first = p.next
+		p.next = (p.next + 1) % len(p.endpoints)
+	}
+	order := make([]int, len(p.endpoints))
+	for i := range order {
+		order[i] = (first + i) % len(p.endpoints)
+	}
+	return order
+}
+
+func (p *hubPool) succeeded(idx int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.verbose && (!p.announced || (p.active != idx && !p.spreadReads)) {
+		log.Printf("Using hub %s", p.endpoints[idx].addr)
+	}
+	p.announced = true
+	if !p.spreadReads {
+		p.active = idx
+	}
+}
+
+func (p *hubPool) failed(idx int, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if len(p.endpoints) > 1 && p.verbose {
+		log.Printf("Hub %s failed: %v", p.endpoints[idx].addr, status.Code(err))
+	}
+	if p.active == idx {
+		p.active = (idx + 1) % len(p.endpoints)
+	}
+}
+
+func (p *hubPool) invoke(ctx context.Context, idx int, method string, args any, reply any, opts ...grpc.CallOption) error {
+	e := p.endpoints[idx]
+	if e.conn == nil {
+		return status.Error(codes.Unavailable, e.err.Error())
+	}
+	if timeout := p.callTimeout(ctx); timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	return e.conn.Invoke(ctx, method, args, reply, opts...)
+}
+
+func (p *hubPool) Invoke(ctx context.Context, method string, args any, reply any, opts ...grpc.CallOption) error {
+	var err error
+	backoff := RETRY_BACKOFF
+	for attempt := 0; ; attempt++ {
+		for _, idx := range p.order(method) {
+			err = p.invoke(ctx, idx, method, args, reply, opts...)
+			if isWrite(method) && status.Code(err) == codes.DeadlineExceeded {
+				// The message may have landed: resending it is up to the caller.
+				return wrapError(err)
+			}
+			if !shouldRetry(ctx, err) {
+				p.succeeded(idx)
+				return wrapError(err)
+			}
+			if shouldFailover(ctx, err) {
+				p.failed(idx, err)
+			}
+		}
+		if attempt >= p.retries {
+			return wrapError(err)
+		}
+		if p.verbose {
+			log.Printf("Retrying %s in %v: %v", method, backoff, status.Code(err))
+		}
+		if sleep(ctx, backoff) != nil {
+			return wrapError(err)
+		}
+		backoff = min(backoff*2, MAX_RETRY_BACKOFF)
+	}
+}
+
+func (p *hubPool) NewStream(ctx context.Context, desc *grpc.StreamDesc, method string, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	var err error
+	for _, idx := range p.order(method) {
+		e := p.endpoints[idx]
+		if e.conn == nil {
+			err = status.Error(codes.Unavailable, e.err.Error())
+			p.failed(idx, err)
+			continue
+		}
+		var stream grpc.ClientStream
+		stream, err = e.conn.NewStream(ctx, desc, method, opts...)
+		if err == nil {
+			p.succeeded(idx)
+			return &poolStream{ClientStream: stream, pool: p, idx: idx}, nil
+		}
+		if !shouldFailover(ctx, err) {
+			return nil, wrapError(err)
+		}
+		p.failed(idx, err)
+	}
+	return nil, wrapError(err)
+}
+
+// poolStream lets the pool know when a stream breaks, so that
+// the next stream is opened on another hub.
+type poolStream struct {
+	grpc.ClientStream
+	pool *hubPool
+	idx  int
+}
+
+func (s *poolStream) RecvMsg(m any) error {
+	err := s.ClientStream.RecvMsg(m)
+	if err != nil && shouldFailover(s.Context(), err) {
+		s.pool.failed(s.idx, err)
+	}
+	return err
+}
+
+/*
+HealthCheck calls GetInfo on every hub and makes the first
+healthy one active. It returns the error of each hub, nil if
+the hub is healthy.
+*/
+func (p *hubPool) HealthCheck(ctx context.Context) map[string]error {
+	errs := make([]error, len(p.endpoints))
+	var wg sync.WaitGroup
+	for i, e := range p.endpoints {
+		if e.conn == nil {
+			errs[i] = e.err
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(ctx, HEALTH_CHECK_TIMEOUT)
+			defer cancel()
+			_, errs[i] = pb.NewHubServiceClient(e.conn).GetInfo(ctx, &pb.HubInfoRequest{DbStats: false})
+		}()
+	}
+	wg.Wait()
+
+	ret := make(map[string]error, len(p.endpoints))
+	healthy := -1
+	for i, e := range p.endpoints {
+		ret[e.addr] = errs[i]
+		if errs[i] == nil && healthy < 0 {
+			healthy = i
+		}
+	}
+	if healthy >= 0 {
+		p.mu.Lock()
+		p.active, p.next = healthy, healthy
+		p.mu.Unlock()
+	}
+	return ret
+}
@@ -0,0 +1,191 @@
+package fctools
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	pb "github.com/vrypan/fargo/farcaster"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+type infoServer struct {
+	pb.UnimplementedHubServiceServer
+	version string
+	code    codes.Code
+	// Number of calls that fail with code, 0 means all of them.
+	failures int
+	delay    time.Duration
+	calls    atomic.Int32
+}
+
+func (s *infoServer) GetInfo(ctx context.Context, req *pb.HubInfoRequest) (*pb.HubInfoResponse, error) {
+	calls := int(s.calls.Add(1))
+	time.Sleep(s.delay)
+	if s.code != codes.OK && (s.failures == 0 || calls <= s.failures) {
+		return nil, status.Error(s.code, "test error")
+	}
+	return &pb.HubInfoResponse{Version: s.version}, nil
+}
+
+func (s *infoServer) SubmitMessage(ctx context.Context, message *pb.Message) (*pb.Message, error) {
+	s.calls.Add(1)
+	time.Sleep(s.delay)
+	return message, nil
+}
+
+func testEndpoint(t *testing.T, addr string, srv pb.HubServiceServer, opts ...grpc.DialOption) *hubEndpoint {
+	listener := bufconn.Listen(1024 * 1024)
+	server := grpc.NewServer()
+	pb.RegisterHubServiceServer(server, srv)
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	opts = append(opts,
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	conn, err := grpc.DialContext(context.Background(), "passthrough:///"+addr, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &hubEndpoint{addr: addr, conn: conn}
+}
+
+func Test_HubPoolFailover(t *testing.T) {
+	pool := &hubPool{endpoints: []*hubEndpoint{
+		testEndpoint(t, "down", &infoServer{code: codes.Unavailable}),
+		testEndpoint(t, "up", &infoServer{version: "up"}),
+	}}
+	defer pool.Close()
+	client := pb.NewHubServiceClient(pool)
+
+	res, err := client.GetInfo(context.Background(), &pb.HubInfoRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Version != "up" || pool.Active() != "up" {
+		t.Fatalf("Expected a failover, got version=%s active=%s", res.Version, pool.Active())
+	}
+}
+
+func Test_HubPoolNoFailover(t *testing.T) {
+	pool := &hubPool{endpoints: []*hubEndpoint{
+		testEndpoint(t, "a", &infoServer{code: codes.NotFound}),
+		testEndpoint(t, "b", &infoServer{version: "b"}),
+	}}
+	defer pool.Close()
+	client := pb.NewHubServiceClient(pool)
+
+	_, err := client.GetInfo(context.Background(), &pb.HubInfoRequest{})
+	if status.Code(err) != codes.NotFound || !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Expected NotFound, got %v", err)
+	}
+	if pool.Active() != "a" {
+		t.Fatalf("Unexpected failover to %s", pool.Active())
+	}
+}
+
+func Test_HubPoolHealthCheck(t *testing.T) {
+	pool := &hubPool{endpoints: []*hubEndpoint{
+		testEndpoint(t, "a", &infoServer{code: codes.Unavailable}),
+		testEndpoint(t, "b", &infoServer{version: "b"}),
+	}}
+	defer pool.Close()
+
+	res := pool.HealthCheck(context.Background())
+	if res["a"] == nil || res["b"] != nil {
+		t.Fatalf("Unexpected health check result: %v", res)
+	}
+	if pool.Active() != "b" {
+		t.Fatalf("Expected b to be active, got %s", pool.Active())
+	}
+}
+
+func Test_HubPoolSpreadReads(t *testing.T) {
+	pool := &hubPool{spreadReads: true, endpoints: []*hubEndpoint{{addr: "a"}, {addr: "b"}}}
+	first := pool.order("/HubService/GetInfo")[0]
+	second := pool.order("/HubService/GetInfo")[0]
+	if first == second {
+		t.Fatal("Reads were not spread")
+	}
+	if pool.order("/HubService/SubmitMessage")[0] != pool.active {
+		t.Fatal("Writes should go to the active hub")
+	}
+}
+
+func Test_HubPoolRetry(t *testing.T) {
+	srv := &infoServer{version: "a", code: codes.Unavailable, failures: 2}
+	pool := &hubPool{retries: 2, endpoints: []*hubEndpoint{testEndpoint(t, "a", srv)}}
+	defer pool.Close()
+	client := pb.NewHubServiceClient(pool)
+
+	if _, err := client.GetInfo(context.Background(), &pb.HubInfoRequest{}); err != nil {
+		t.Fatal(err)
+	}
+	if n := srv.calls.Load(); n != 3 {
+		t.Fatalf("Expected 3 calls, got %d", n)
+	}
+
+	srv.failures = 0
+	_, err := client.GetInfo(context.Background(), &pb.HubInfoRequest{})
+	if !errors.Is(err, ErrUnavailable) {
+		t.Fatalf("Expected ErrUnavailable, got %v", err)
+	}
+}
+
+func Test_HubPoolTimeout(t *testing.T) {
+	srv := &infoServer{version: "a", delay: 100 * time.Millisecond}
+	pool := &hubPool{timeout: 10 * time.Millisecond, endpoints: []*hubEndpoint{testEndpoint(t, "a", srv)}}
+	defer pool.Close()
+	client := pb.NewHubServiceClient(pool)
+
+	_, err := client.GetInfo(context.Background(), &pb.HubInfoRequest{})
+	if !errors.Is(err, ErrTimeout) {
+		t.Fatalf("Expected ErrTimeout, got %v", err)
+	}
+
+	ctx := context.WithValue(context.Background(), callTimeoutKey{}, time.Second)
+	if _, err := client.GetInfo(ctx, &pb.HubInfoRequest{}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func Test_HubPoolDialError(t *testing.T) {
+	pool := &hubPool{endpoints: []*hubEndpoint{
+		{addr: "bad", err: errors.New("dial error")},
+		testEndpoint(t, "b", &infoServer{version: "b"}),
+	}}
+	defer pool.Close()
+	client := pb.NewHubServiceClient(pool)
+
+	if _, err := client.GetInfo(context.Background(), &pb.HubInfoRequest{}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func Test_HubPoolWriteTimeout(t *testing.T) {
+	a := &infoServer{version: "a", delay: 100 * time.Millisecond}
+	b := &infoServer{version: "b"}
+	pool := &hubPool{timeout: 10 * time.Millisecond, retries: 2,
+		endpoints: []*hubEndpoint{testEndpoint(t, "a", a), testEndpoint(t, "b", b)}}
+	defer pool.Close()
+	client := pb.NewHubServiceClient(pool)
+
+	_, err := client.SubmitMessage(context.Background(), &pb.Message{})
+	if !errors.Is(err, ErrTimeout) {
+		t.Fatalf("Expected ErrTimeout, got %v", err)
+	}
+	if a.calls.Load() != 1 || b.calls.Load() != 0 {
+		t.Fatalf("Expected a single attempt, got %d on a and %d on b", a.calls.Load(), b.calls.Load())
+	}
+}
@@ -61,8 +61,8 @@ func NewReactions() *Reactions {
 }
 
 /*
-Populates a CastGroup with recent likes from an Fid.
-Head is set to nil.
+Populates Reactions with the count most recent reactions from an Fid.
+count == 0 fetches all the reactions of the Fid.
 */
 func (reactions *Reactions) FromFid(hub *FarcasterHub, fid uint64, reactionType string, count uint32) *Reactions {
 	if hub == nil {
@@ -78,10 +78,31 @@ func (reactions *Reactions) FromFid(hub *FarcasterHub, fid uint64, reactionType
 
 	return reactions
 }
+
+/*
+Populates Reactions with the count most recent reactions to a cast.
+count == 0 fetches all the reactions.
+*/
+func (reactions *Reactions) FromTarget(hub *FarcasterHub, castId *pb.CastId, reactionType string, count uint32) *Reactions {
+	if hub == nil {
+		hub = NewFarcasterHub()
+		defer hub.Close()
+	}
+
+	if messages, err := hub.GetReactionsByTarget(castId, reactionType, count); err == nil {
+		for _, reaction := range messages {
+			reactions.Messages = append(reactions.Messages, &Reaction{Message: reaction})
+		}
+	}
+
+	return reactions
+}
 func (reactions *Reactions) CollectFnames(hub *FarcasterHub) *Reactions {
+	fids := make([]uint64, 0, len(reactions.Messages))
 	for _, msg := range reactions.Messages {
-		reactions.Fnames[msg.Message.Data.Fid], _ = hub.PrxGetUserDataStr(msg.Message.Data.Fid, "USER_DATA_TYPE_USERNAME")
+		fids = append(fids, msg.Message.Data.Fid)
 	}
+	collectFnames(hub, reactions.Fnames, fids)
 	return reactions
 }
 func (reactions *Reactions) CastIds() []*pb.CastId {
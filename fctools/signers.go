@@ -0,0 +1,106 @@
+package fctools
+
+import (
+	"encoding/hex"
+	"errors"
+	"math/big"
+	"strconv"
+	"time"
+
+	pb "github.com/vrypan/fargo/farcaster"
+)
+
+var ERR_SIGNER_NOT_ACTIVE = errors.New("Not an active signer")
+
+// Signer key types, as defined in the KeyRegistry contract.
+var SignerKeyTypes = map[uint32]string{
+	1: "ed25519",
+}
+
+// Signer wraps an on-chain signer event.
+type Signer struct {
+	Event *pb.OnChainEvent
+}
+
+func (s *Signer) Key() []byte {
+	return s.Event.GetSignerEventBody().GetKey()
+}
+
+func (s *Signer) KeyHex() string {
+	return "0x" + hex.EncodeToString(s.Key())
+}
+
+func (s *Signer) KeyType() string {
+	keyType := s.Event.GetSignerEventBody().GetKeyType()
+	if name, ok := SignerKeyTypes[keyType]; ok {
+		return name
+	}
+	return strconv.FormatUint(uint64(keyType), 10)
+}
+
+/*
+AppFid returns the fid of the app that requested the signer, decoded
+from the SignedKeyRequest metadata (metadata type 1), which is the
+ABI-encoded tuple (uint256 requestFid, address requestSigner,
+bytes signature, uint256 deadline).
+*/
+func (s *Signer) AppFid() (uint64, bool) {
+	body := s.Event.GetSignerEventBody()
+	metadata := body.GetMetadata()
+	if body.GetMetadataType() != 1 || len(metadata) < 128 {
+		return 0, false
+	}
+	// The tuple has a dynamic member, so it is preceded by its offset.
+	offset := new(big.Int).SetBytes(metadata[:32])
+	if !offset.IsUint64() || offset.Uint64() != 32 {
+		return 0, false
+	}
+	requestFid := new(big.Int).SetBytes(metadata[32:64])
+	if !requestFid.IsUint64() {
+		return 0, false
+	}
+	return requestFid.Uint64(), true
+}
+
+func (s *Signer) Active() bool {
+	return s.Event.GetSignerEventBody().GetEventType() == pb.SignerEventType_SIGNER_EVENT_TYPE_ADD
+}
+
+func (s *Signer) AddedAt() time.Time {
+	return time.Unix(int64(s.Event.BlockTimestamp), 0)
+}
+
+/*
+GetOnChainSigner returns the event that added signer to fid.
+If signer is not an active signer of fid, it returns ERR_SIGNER_NOT_ACTIVE.
+*/
+func (hub FarcasterHub) GetOnChainSigner(fid uint64, signer []byte) (*pb.OnChainEvent, error) {
+	event, err := hub.client.GetOnChainSigner(hub.ctx, &pb.SignerRequest{Fid: fid, Signer: signer})
+	if errors.Is(err, ErrNotFound) {
+		return nil, ERR_SIGNER_NOT_ACTIVE
+	}
+	if err != nil {
+		return nil, err
+	}
+	if event.GetSignerEventBody().GetEventType() != pb.SignerEventType_SIGNER_EVENT_TYPE_ADD {
+		return nil, ERR_SIGNER_NOT_ACTIVE
+	}
+	return event, nil
+}
+
+// GetOnChainSignersByFid returns the events of all the active signers of fid.
+func (hub FarcasterHub) GetOnChainSignersByFid(fid uint64) ([]*pb.OnChainEvent, error) {
+	events := make([]*pb.OnChainEvent, 0)
+	var pageToken []byte
+	for {
+		res, err := hub.client.GetOnChainSignersByFid(hub.ctx, &pb.FidRequest{Fid: fid, PageToken: pageToken})
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, res.Events...)
+		pageToken = res.GetNextPageToken()
+		if len(pageToken) == 0 || len(res.Events) == 0 {
+			return events, nil
+		}
+	}
+}
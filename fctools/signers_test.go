@@ -0,0 +1,50 @@
+package fctools
+
+import (
+	"testing"
+
+	pb "github.com/vrypan/fargo/farcaster"
+)
+
+func Test_Signer(t *testing.T) {
+	signer := Signer{Event: &pb.OnChainEvent{
+		Type:           pb.OnChainEventType_EVENT_TYPE_SIGNER,
+		BlockTimestamp: 1700000000,
+		Body: &pb.OnChainEvent_SignerEventBody{SignerEventBody: &pb.SignerEventBody{
+			Key:       []byte{0xab, 0xcd},
+			KeyType:   1,
+			EventType: pb.SignerEventType_SIGNER_EVENT_TYPE_ADD,
+		}},
+	}}
+	if signer.KeyHex() != "0xabcd" {
+		t.Fatalf("Unexpected key: %s", signer.KeyHex())
+	}
+	if signer.KeyType() != "ed25519" {
+		t.Fatalf("Unexpected key type: %s", signer.KeyType())
+	}
+	if !signer.Active() {
+		t.Fatal("Expected an active signer")
+	}
+	if signer.AddedAt().Unix() != 1700000000 {
+		t.Fatalf("Unexpected added at: %v", signer.AddedAt())
+	}
+}
+
+func Test_SignerAppFid(t *testing.T) {
+	metadata := make([]byte, 256)
+	metadata[31] = 32
+	metadata[63] = 9 // requestFid
+	signer := Signer{Event: &pb.OnChainEvent{
+		Body: &pb.OnChainEvent_SignerEventBody{SignerEventBody: &pb.SignerEventBody{
+			Metadata:     metadata,
+			MetadataType: 1,
+		}},
+	}}
+	if fid, ok := signer.AppFid(); !ok || fid != 9 {
+		t.Fatalf("Unexpected app fid: %d %v", fid, ok)
+	}
+	signer.Event.GetSignerEventBody().MetadataType = 0
+	if _, ok := signer.AppFid(); ok {
+		t.Fatal("Decoded app fid of unknown metadata type")
+	}
+}
@@ -0,0 +1,135 @@
+package fctools
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+/*
+SortMode is the order casts are listed in by CastGroup.Hashes, the
+renderers, and the replies of each cast in a thread.
+SORT_NONE keeps the order of the hub for lists, and sorts the
+rest by timestamp.
+*/
+type SortMode string
+
+const (
+	SORT_NONE      SortMode = ""
+	SORT_TIME_ASC  SortMode = "time"
+	SORT_TIME_DESC SortMode = "time-desc"
+	SORT_REACTIONS SortMode = "reactions"
+	SORT_REPLIES   SortMode = "replies"
+)
+
+var SortModes = []SortMode{SORT_TIME_ASC, SORT_TIME_DESC, SORT_REACTIONS, SORT_REPLIES}
+
+func ParseSortMode(s string) (SortMode, error) {
+	mode := SortMode(strings.ToLower(strings.TrimSpace(s)))
+	if mode == SORT_NONE {
+		return mode, nil
+	}
+	for _, m := range SortModes {
+		if mode == m {
+			return mode, nil
+		}
+	}
+	return SORT_NONE, fmt.Errorf("Unknown sort mode %q, expected one of %v", s, SortModes)
+}
+
+// needsStats returns true for the modes that sort by CastStats.
+func (mode SortMode) needsStats() bool {
+	return mode == SORT_REACTIONS || mode == SORT_REPLIES
+}
+
+/*
+SortBy sorts the group's list (Ordered) and the replies of every cast
+by mode. The reactions and replies modes sort by CastStats, most first;
+the stats of casts that don't have them are fetched from hub (a new
+hub connection if hub is nil). Stats already set are kept.
+Ties are broken by timestamp and then by hash, so the order is
+always the same for the same casts.
+*/
+func (grp *CastGroup) SortBy(hub *FarcasterHub, mode SortMode) *CastGroup {
+	grp.Sort = mode
+	if mode == SORT_NONE {
+		return grp
+	}
+	if mode.needsStats() {
+		missing := NewCastGroup()
+		for h, c := range grp.Messages {
+			if c.Stats == nil {
+				missing.Messages[h] = c
+			}
+		}
+		if len(missing.Messages) > 0 {
+			if hub == nil {
+				hub = NewFarcasterHub()
+				defer hub.Close()
+			}
+			missing.CollectStats(hub)
+		}
+	}
+	grp.sortHashes(grp.Ordered)
+	for _, c := range grp.Messages {
+		grp.sortHashes(c.Replies)
+	}
+	return grp
+}
+
+/*
+Hashes returns the hashes of the casts in the group: Ordered if it is
+set, otherwise all the casts, sorted by grp.Sort (by timestamp
+for SORT_NONE).
+*/
+func (grp *CastGroup) Hashes() []Hash {
+	if len(grp.Ordered) > 0 {
+		return grp.Ordered
+	}
+	hashes := make([]Hash, 0, len(grp.Messages))
+	for h := range grp.Messages {
+		hashes = append(hashes, h)
+	}
+	grp.sortHashes(hashes)
+	return hashes
+}
+
+func (grp *CastGroup) sortHashes(hashes []Hash) {
+	sort.SliceStable(hashes, func(i, j int) bool {
+		return grp.less(hashes[i], hashes[j])
+	})
+}
+
+func (grp *CastGroup) less(a, b Hash) bool {
+	ca, cb := grp.Messages[a], grp.Messages[b]
+	if ca == nil || cb == nil {
+		// Casts missing from the group go last.
+		return ca != nil
+	}
+	sa, sb := ca.Stats, cb.Stats
+	if sa == nil {
+		sa = &CastStats{}
+	}
+	if sb == nil {
+		sb = &CastStats{}
+	}
+	switch grp.Sort {
+	case SORT_REACTIONS:
+		if ra, rb := sa.Likes+sa.Recasts, sb.Likes+sb.Recasts; ra != rb {
+			return ra > rb
+		}
+	case SORT_REPLIES:
+		if sa.Replies != sb.Replies {
+			return sa.Replies > sb.Replies
+		}
+	}
+	ta, tb := ca.Message.Data.Timestamp, cb.Message.Data.Timestamp
+	if ta != tb {
+		if grp.Sort == SORT_TIME_DESC {
+			return ta > tb
+		}
+		return ta < tb
+	}
+	return bytes.Compare(a[:], b[:]) < 0
+}
@@ -0,0 +1,106 @@
+package fctools
+
+import (
+	"slices"
+	"testing"
+
+	pb "github.com/vrypan/fargo/farcaster"
+)
+
+func sortTestGroup() *CastGroup {
+	grp := NewCastGroup()
+	add := func(b byte, timestamp uint32, likes int, replies int) {
+		h := Hash{b}
+		grp.Messages[h] = &Cast{
+			Message: &pb.Message{Hash: h.Bytes(), Data: &pb.MessageData{Fid: 280, Timestamp: timestamp}},
+			Stats:   &CastStats{Likes: likes, Replies: replies},
+		}
+	}
+	add(1, 30, 0, 5)
+	add(2, 10, 7, 0)
+	add(3, 20, 3, 1)
+	add(4, 20, 3, 1)
+	grp.Messages[Hash{1}].Replies = []Hash{{4}, {2}, {3}}
+	return grp
+}
+
+func Test_ParseSortMode(t *testing.T) {
+	for _, s := range []string{"", "time", "TIME-DESC", "reactions", "replies"} {
+		if _, err := ParseSortMode(s); err != nil {
+			t.Errorf("%q: unexpected error %v", s, err)
+		}
+	}
+	if _, err := ParseSortMode("random"); err == nil {
+		t.Error("Expected an error for an unknown sort mode")
+	}
+}
+
+func Test_SortBy(t *testing.T) {
+	tests := []struct {
+		mode     SortMode
+		expected []Hash
+	}{
+		{SORT_NONE, []Hash{{2}, {3}, {4}, {1}}},
+		{SORT_TIME_ASC, []Hash{{2}, {3}, {4}, {1}}},
+		{SORT_TIME_DESC, []Hash{{1}, {3}, {4}, {2}}},
+		{SORT_REACTIONS, []Hash{{2}, {3}, {4}, {1}}},
+		{SORT_REPLIES, []Hash{{1}, {3}, {4}, {2}}},
+	}
+	for _, test := range tests {
+		grp := sortTestGroup().SortBy(nil, test.mode)
+		if hashes := grp.Hashes(); !slices.Equal(hashes, test.expected) {
+			t.Errorf("%q: expected %v, got %v", test.mode, test.expected, hashes)
+		}
+	}
+
+	grp := sortTestGroup().SortBy(nil, SORT_TIME_DESC)
+	if replies := grp.Messages[Hash{1}].Replies; !slices.Equal(replies, []Hash{{3}, {4}, {2}}) {
+		t.Errorf("Replies not sorted: %v", replies)
+	}
+
+	// Ordered lists keep their order unless sorted.
+	grp = sortTestGroup()
+	grp.Ordered = []Hash{{1}, {2}, {3}}
+	if hashes := grp.Hashes(); !slices.Equal(hashes, []Hash{{1}, {2}, {3}}) {
+		t.Errorf("Ordered changed: %v", hashes)
+	}
+	if hashes := grp.SortBy(nil, SORT_TIME_ASC).Hashes(); !slices.Equal(hashes, []Hash{{2}, {3}, {1}}) {
+		t.Errorf("Ordered not sorted: %v", hashes)
+	}
+}
+
+func Test_JsonListStable(t *testing.T) {
+	first, err := sortTestGroup().JsonList(true, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 10; i++ {
+		s, _ := sortTestGroup().JsonList(true, false)
+		if string(s) != string(first) {
+			t.Fatal("JsonList output changed between runs")
+		}
+	}
+}
+
+func Test_SortByMissingStats(t *testing.T) {
+	testHub(t)
+	grp := NewCastGroup()
+	for _, m := range threadCasts[:3] {
+		grp.Messages[Hash(m.Hash)] = &Cast{Message: m}
+	}
+	// Stats already set are kept, the rest are fetched.
+	head := grp.Messages[Hash(threadHead.Hash)]
+	head.Stats = &CastStats{Likes: 100}
+	grp.SortBy(nil, SORT_REACTIONS)
+	if head.Stats.Likes != 100 || head.Stats.Replies != 0 {
+		t.Fatalf("Stats of the head were fetched again: %+v", head.Stats)
+	}
+	for h, c := range grp.Messages {
+		if c.Stats == nil {
+			t.Fatalf("Stats of 0x%s were not fetched", h.String())
+		}
+	}
+	if hashes := grp.Hashes(); hashes[0] != Hash(threadHead.Hash) {
+		t.Fatalf("Unexpected order: %v", hashes)
+	}
+}
@@ -0,0 +1,135 @@
+package fctools
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	pb "github.com/vrypan/fargo/farcaster"
+)
+
+// StoreUsage is the number of messages used and allowed in a store.
+type StoreUsage struct {
+	Store             string `json:"store"`
+	Used              uint64 `json:"used"`
+	Limit             uint64 `json:"limit"`
+	EarliestTimestamp uint32 `json:"earliestTimestamp,omitempty"`
+}
+
+func (s StoreUsage) Available() uint64 {
+	if s.Used >= s.Limit {
+		return 0
+	}
+	return s.Limit - s.Used
+}
+
+// StoreName returns the name used for a StoreType, ex. "casts".
+func StoreName(storeType pb.StoreType) string {
+	return strings.ToLower(strings.TrimPrefix(storeType.String(), "STORE_TYPE_"))
+}
+
+func (hub FarcasterHub) GetCurrentStorageLimitsByFid(fid uint64) (*pb.StorageLimitsResponse, error) {
+	return hub.client.GetCurrentStorageLimitsByFid(hub.ctx, &pb.FidRequest{Fid: fid})
+}
+
+// StorageUsage returns the usage of each store of fid.
+func StorageUsage(limits *pb.StorageLimitsResponse) []StoreUsage {
+	usage := make([]StoreUsage, 0, len(limits.GetLimits()))
+	for _, l := range limits.GetLimits() {
+		usage = append(usage, StoreUsage{
+			Store:             StoreName(l.StoreType),
+			Used:              l.Used,
+			Limit:             l.Limit,
+			EarliestTimestamp: uint32(l.EarliestTimestamp),
+		})
+	}
+	return usage
+}
+
+/*
+Pruned returns how many of the oldest messages in the store
+the hub would prune if n new messages were added.
+*/
+func (s StoreUsage) Pruned(n uint64) uint64 {
+	if s.Used+n <= s.Limit {
+		return 0
+	}
+	return s.Used + n - s.Limit
+}
+
+/*
+StoreTypeOf returns the store messages of messageType are kept in.
+Remove messages replace the message they remove, so they don't use
+extra storage, and STORE_TYPE_NONE is returned for them.
+*/
+func StoreTypeOf(messageType pb.MessageType) pb.StoreType {
+	switch messageType {
+	case pb.MessageType_MESSAGE_TYPE_CAST_ADD:
+		return pb.StoreType_STORE_TYPE_CASTS
+	case pb.MessageType_MESSAGE_TYPE_LINK_ADD:
+		return pb.StoreType_STORE_TYPE_LINKS
+	case pb.MessageType_MESSAGE_TYPE_REACTION_ADD:
+		return pb.StoreType_STORE_TYPE_REACTIONS
+	case pb.MessageType_MESSAGE_TYPE_USER_DATA_ADD:
+		return pb.StoreType_STORE_TYPE_USER_DATA
+	case pb.MessageType_MESSAGE_TYPE_VERIFICATION_ADD_ETH_ADDRESS:
+		return pb.StoreType_STORE_TYPE_VERIFICATIONS
+	case pb.MessageType_MESSAGE_TYPE_USERNAME_PROOF:
+		return pb.StoreType_STORE_TYPE_USERNAME_PROOFS
+	default:
+		return pb.StoreType_STORE_TYPE_NONE
+	}
+}
+
+/*
+Replaces returns true if a message with data would replace a message
+fid already has, instead of using more storage: setting a user data
+type that is already set, or adding a link or reaction that exists.
+*/
+func (hub FarcasterHub) Replaces(data *pb.MessageData) (bool, error) {
+	var err error
+	switch data.Type {
+	case pb.MessageType_MESSAGE_TYPE_USER_DATA_ADD:
+		_, err = hub.client.GetUserData(hub.ctx, &pb.UserDataRequest{
+			Fid:          data.Fid,
+			UserDataType: data.GetUserDataBody().GetType(),
+		})
+	case pb.MessageType_MESSAGE_TYPE_LINK_ADD:
+		body := data.GetLinkBody()
+		_, err = hub.client.GetLink(hub.ctx, &pb.LinkRequest{
+			Fid:      data.Fid,
+			LinkType: body.GetType(),
+			Target:   &pb.LinkRequest_TargetFid{TargetFid: body.GetTargetFid()},
+		})
+	case pb.MessageType_MESSAGE_TYPE_REACTION_ADD:
+		body := data.GetReactionBody()
+		req := &pb.ReactionRequest{Fid: data.Fid, ReactionType: body.GetType()}
+		if castId := body.GetTargetCastId(); castId != nil {
+			req.Target = &pb.ReactionRequest_TargetCastId{TargetCastId: castId}
+		} else {
+			req.Target = &pb.ReactionRequest_TargetUrl{TargetUrl: body.GetTargetUrl()}
+		}
+		_, err = hub.client.GetReaction(hub.ctx, req)
+	default:
+		return false, nil
+	}
+	if errors.Is(err, ErrNotFound) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// GetStoreUsage returns the usage of one of the stores of fid.
+func (hub FarcasterHub) GetStoreUsage(fid uint64, storeType pb.StoreType) (*StoreUsage, error) {
+	limits, err := hub.GetCurrentStorageLimitsByFid(fid)
+	if err != nil {
+		return nil, err
+	}
+	for _, l := range limits.GetLimits() {
+		if l.StoreType == storeType {
+			usage := StorageUsage(&pb.StorageLimitsResponse{Limits: []*pb.StorageLimit{l}})[0]
+			return &usage, nil
+		}
+	}
+	return nil, fmt.Errorf("No storage limits for %s", StoreName(storeType))
+}
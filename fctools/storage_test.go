@@ -0,0 +1,102 @@
+package fctools
+
+import (
+	"testing"
+
+	pb "github.com/vrypan/fargo/farcaster"
+)
+
+func Test_StoreUsage(t *testing.T) {
+	usage := StorageUsage(&pb.StorageLimitsResponse{Limits: []*pb.StorageLimit{
+		{StoreType: pb.StoreType_STORE_TYPE_CASTS, Limit: 5000, Used: 4999},
+	}})
+	if len(usage) != 1 || usage[0].Store != "casts" {
+		t.Fatalf("Unexpected usage: %v", usage)
+	}
+	if usage[0].Available() != 1 {
+		t.Fatalf("Unexpected available: %d", usage[0].Available())
+	}
+	if n := usage[0].Pruned(1); n != 0 {
+		t.Fatalf("Unexpected pruned: %d", n)
+	}
+	if n := usage[0].Pruned(3); n != 2 {
+		t.Fatalf("Unexpected pruned: %d", n)
+	}
+}
+
+func Test_StoreTypeOf(t *testing.T) {
+	if StoreTypeOf(pb.MessageType_MESSAGE_TYPE_CAST_ADD) != pb.StoreType_STORE_TYPE_CASTS {
+		t.Fatal("Unexpected store for casts")
+	}
+	if StoreTypeOf(pb.MessageType_MESSAGE_TYPE_CAST_REMOVE) != pb.StoreType_STORE_TYPE_NONE {
+		t.Fatal("Remove messages should not need storage")
+	}
+}
+
+func Test_Replaces(t *testing.T) {
+	fakeHub := testHub(t)
+	follow := fixtureMessage(280, pb.MessageType_MESSAGE_TYPE_LINK_ADD, func(d *pb.MessageData) {
+		d.Body = &pb.MessageData_LinkBody{LinkBody: &pb.LinkBody{
+			Type: LINK_TYPE_FOLLOW, Target: &pb.LinkBody_TargetFid{TargetFid: 3},
+		}}
+	})
+	fakeHub.AddMessages(follow)
+	var like *pb.Message
+	for _, m := range fakeHub.Messages() {
+		if m.Data.Type == pb.MessageType_MESSAGE_TYPE_REACTION_ADD {
+			like = m
+		}
+	}
+
+	hub := NewFarcasterHub()
+	defer hub.Close()
+	userData := func(t pb.UserDataType) *pb.MessageData {
+		return &pb.MessageData{
+			Type: pb.MessageType_MESSAGE_TYPE_USER_DATA_ADD,
+			Fid:  280,
+			Body: &pb.MessageData_UserDataBody{UserDataBody: &pb.UserDataBody{Type: t, Value: "new"}},
+		}
+	}
+	followOf := func(fid uint64) *pb.MessageData {
+		return &pb.MessageData{
+			Type: pb.MessageType_MESSAGE_TYPE_LINK_ADD,
+			Fid:  280,
+			Body: &pb.MessageData_LinkBody{LinkBody: &pb.LinkBody{
+				Type: LINK_TYPE_FOLLOW, Target: &pb.LinkBody_TargetFid{TargetFid: fid},
+			}},
+		}
+	}
+	likeOf := func(target *pb.Message) *pb.MessageData {
+		return &pb.MessageData{
+			Type: pb.MessageType_MESSAGE_TYPE_REACTION_ADD,
+			Fid:  280,
+			Body: &pb.MessageData_ReactionBody{ReactionBody: &pb.ReactionBody{
+				Type:   pb.ReactionType_REACTION_TYPE_LIKE,
+				Target: &pb.ReactionBody_TargetCastId{TargetCastId: &pb.CastId{Fid: target.Data.Fid, Hash: target.Hash}},
+			}},
+		}
+	}
+	likedCast := &pb.Message{Hash: like.Data.GetReactionBody().GetTargetCastId().Hash, Data: &pb.MessageData{Fid: 280}}
+
+	tests := []struct {
+		name     string
+		data     *pb.MessageData
+		replaces bool
+	}{
+		{"username is set", userData(pb.UserDataType_USER_DATA_TYPE_USERNAME), true},
+		{"url is not set", userData(pb.UserDataType_USER_DATA_TYPE_URL), false},
+		{"follow exists", followOf(3), true},
+		{"new follow", followOf(20396), false},
+		{"like exists", likeOf(likedCast), true},
+		{"new like", likeOf(threadHead), false},
+	}
+	for _, test := range tests {
+		replaces, err := hub.Replaces(test.data)
+		if err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+		if replaces != test.replaces {
+			t.Errorf("%s: expected %v, got %v", test.name, test.replaces, replaces)
+		}
+	}
+}
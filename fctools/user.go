@@ -2,6 +2,8 @@ package fctools
 
 import (
 	"encoding/json"
+	"fmt"
+	"regexp"
 	"strconv"
 	"strings"
 
@@ -9,6 +11,37 @@ import (
 	"google.golang.org/protobuf/encoding/protojson"
 )
 
+// Max length in bytes of UserDataBody.value, per USER_DATA_TYPE_*.
+var UserDataMaxLen = map[string]int{
+	"USER_DATA_TYPE_PFP":      256,
+	"USER_DATA_TYPE_DISPLAY":  32,
+	"USER_DATA_TYPE_BIO":      256,
+	"USER_DATA_TYPE_URL":      256,
+	"USER_DATA_TYPE_USERNAME": 20,
+	"USER_DATA_TYPE_LOCATION": 32,
+}
+
+var locationRe = regexp.MustCompile(`^geo:-?\d{1,2}\.\d{2},-?\d{1,3}\.\d{2}$`)
+
+/*
+ValidateUserData checks value against the limits hubs enforce
+for userDataType (ex. "USER_DATA_TYPE_BIO").
+An empty value is valid: it clears the field.
+*/
+func ValidateUserData(userDataType string, value string) error {
+	maxLen, ok := UserDataMaxLen[userDataType]
+	if !ok {
+		return fmt.Errorf("Unknown user data type: %s", userDataType)
+	}
+	if len(value) > maxLen {
+		return fmt.Errorf("%s is %d bytes long, max is %d", userDataType, len(value), maxLen)
+	}
+	if userDataType == "USER_DATA_TYPE_LOCATION" && value != "" && !locationRe.MatchString(value) {
+		return fmt.Errorf("%s must look like geo:<lat>,<long> with 2 decimals, ex. geo:37.97,23.72", userDataType)
+	}
+	return nil
+}
+
 type User struct {
 	Fid      uint64
 	UserData map[string]*pb.Message
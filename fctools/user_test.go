@@ -5,7 +5,11 @@ import (
 )
 
 func Test_UserJson(t *testing.T) {
+	testHub(t)
 	u := NewUser().FromFid(280).FetchUserData(nil, nil)
+	if u.Value("USER_DATA_TYPE_USERNAME") != "vrypan" {
+		t.Fatalf("Expected username vrypan, got %q", u.Value("USER_DATA_TYPE_USERNAME"))
+	}
 	s, err := u.Json("", false, false)
 	if err != nil {
 		t.Fatal(err)
@@ -14,6 +18,33 @@ func Test_UserJson(t *testing.T) {
 }
 
 func Test_UserString(t *testing.T) {
+	testHub(t)
 	u := NewUser().FromFname(nil, "vrypan").FetchUserData(nil, nil)
+	if u.Fid != 280 {
+		t.Fatalf("Expected fid=280, got fid=%d", u.Fid)
+	}
 	t.Logf("\n%s", u)
 }
+
+func Test_ValidateUserData(t *testing.T) {
+	valid := map[string]string{
+		"USER_DATA_TYPE_BIO":      "Hello",
+		"USER_DATA_TYPE_DISPLAY":  "",
+		"USER_DATA_TYPE_LOCATION": "geo:37.97,23.72",
+	}
+	for k, v := range valid {
+		if err := ValidateUserData(k, v); err != nil {
+			t.Errorf("%s=%q: unexpected error %v", k, v, err)
+		}
+	}
+	invalid := map[string]string{
+		"USER_DATA_TYPE_DISPLAY":  "0123456789012345678901234567890123",
+		"USER_DATA_TYPE_LOCATION": "Athens",
+		"USER_DATA_TYPE_NONE":     "x",
+	}
+	for k, v := range invalid {
+		if err := ValidateUserData(k, v); err == nil {
+			t.Errorf("%s=%q: expected an error", k, v)
+		}
+	}
+}
@@ -0,0 +1,237 @@
+package fctools
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+
+	pb "github.com/vrypan/fargo/farcaster"
+	"golang.org/x/crypto/sha3"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+type Verification struct {
+	Message *pb.Message
+}
+
+type Verifications struct {
+	Messages []*Verification
+}
+
+func (v *Verification) body() *pb.VerificationAddAddressBody {
+	return v.Message.Data.GetVerificationAddAddressBody()
+}
+
+// Protocol returns "ethereum" or "solana".
+func (v *Verification) Protocol() string {
+	return strings.ToLower(strings.TrimPrefix(v.body().GetProtocol().String(), "PROTOCOL_"))
+}
+
+/*
+Address returns the verified address in the protocol's usual format:
+EIP-55 checksummed hex for Ethereum, base58 for Solana.
+*/
+func (v *Verification) Address() string {
+	return FormatAddress(v.body().GetProtocol(), v.body().GetAddress())
+}
+
+// Type returns "eoa" or "contract".
+func (v *Verification) Type() string {
+	if v.body().GetVerificationType() == 1 {
+		return "contract"
+	}
+	return "eoa"
+}
+
+func (v *Verification) String() string {
+	s := v.Protocol() + " " + v.Address() + " " + v.Type()
+	if chainId := v.body().GetChainId(); chainId != 0 {
+		s += " (chain " + strconv.FormatUint(uint64(chainId), 10) + ")"
+	}
+	return s
+}
+
+func NewVerifications() *Verifications {
+	return &Verifications{
+		Messages: make([]*Verification, 0),
+	}
+}
+
+/*
+Populates Verifications with the count most recent verified addresses of an Fid.
+count == 0 fetches all of them.
+*/
+func (verifications *Verifications) FromFid(hub *FarcasterHub, fid uint64, count uint32) *Verifications {
+	if hub == nil {
+		hub = NewFarcasterHub()
+		defer hub.Close()
+	}
+	if messages, err := hub.GetVerificationsByFid(fid, count); err == nil {
+		for _, m := range messages {
+			verifications.Messages = append(verifications.Messages, &Verification{Message: m})
+		}
+	}
+	return verifications
+}
+
+func (verifications *Verifications) String() string {
+	var builder strings.Builder
+	for _, v := range verifications.Messages {
+		builder.WriteString(v.String())
+		builder.WriteString("\n")
+	}
+	return builder.String()
+}
+
+/*
+JsonList returns the verification messages as JSON. If hexHashes is true,
+addresses are also converted to their protocol format, and the claim
+signature and block hash to hex.
+*/
+func (verifications *Verifications) JsonList(hexHashes bool, realTimestamps bool) ([]byte, error) {
+	groupData := make([]interface{}, len(verifications.Messages))
+	for idx, v := range verifications.Messages {
+		var jsonData interface{}
+		json_bytes, err := protojson.Marshal(v.Message)
+		if err != nil {
+			return nil, err
+		}
+		err = json.Unmarshal(json_bytes, &jsonData)
+		if err != nil {
+			return nil, err
+		}
+		jsonPretty(jsonData, hexHashes, realTimestamps)
+		if hexHashes {
+			data, _ := jsonData.(map[string]interface{})["data"].(map[string]interface{})
+			if body, ok := data["verificationAddAddressBody"].(map[string]interface{}); ok {
+				body["address"] = v.Address()
+				body["claimSignature"] = "0x" + hex.EncodeToString(v.body().GetClaimSignature())
+				body["blockHash"] = "0x" + hex.EncodeToString(v.body().GetBlockHash())
+			}
+		}
+		groupData[idx] = jsonData
+	}
+	return json.MarshalIndent(groupData, "", "  ")
+}
+
+/*
+JsonOnChainEvent formats event like the Json* functions: with hexHashes,
+hashes and addresses are shown in hex instead of base64, and with
+realTimestamps the block timestamp is shown as a date.
+*/
+func JsonOnChainEvent(event *pb.OnChainEvent, hexHashes bool, realTimestamps bool) ([]byte, error) {
+	jsonBytes, err := protojson.Marshal(event)
+	if err != nil {
+		return nil, err
+	}
+	var jsonData map[string]interface{}
+	if err := json.Unmarshal(jsonBytes, &jsonData); err != nil {
+		return nil, err
+	}
+	if hexHashes {
+		hexField := func(m map[string]interface{}, k string, b []byte, format func([]byte) string) {
+			if _, ok := m[k]; ok {
+				m[k] = format(b)
+			}
+		}
+		toHex := func(b []byte) string { return "0x" + hex.EncodeToString(b) }
+		hexField(jsonData, "blockHash", event.BlockHash, toHex)
+		hexField(jsonData, "transactionHash", event.TransactionHash, toHex)
+		if body, ok := jsonData["idRegisterEventBody"].(map[string]interface{}); ok {
+			idBody := event.GetIdRegisterEventBody()
+			hexField(body, "to", idBody.GetTo(), EthChecksumAddress)
+			hexField(body, "from", idBody.GetFrom(), EthChecksumAddress)
+			hexField(body, "recoveryAddress", idBody.GetRecoveryAddress(), EthChecksumAddress)
+		}
+	}
+	if realTimestamps {
+		jsonData["blockTimestamp"] = time.Unix(int64(event.BlockTimestamp), 0)
+	}
+	return json.MarshalIndent(jsonData, "", "  ")
+}
+
+// FormatAddress formats address in the usual format of protocol.
+func FormatAddress(protocol pb.Protocol, address []byte) string {
+	if protocol == pb.Protocol_PROTOCOL_SOLANA {
+		return base58Encode(address)
+	}
+	return EthChecksumAddress(address)
+}
+
+// EthChecksumAddress returns the EIP-55 mixed-case hex encoding of address.
+func EthChecksumAddress(address []byte) string {
+	lower := hex.EncodeToString(address)
+	hasher := sha3.NewLegacyKeccak256()
+	hasher.Write([]byte(lower))
+	hash := hex.EncodeToString(hasher.Sum(nil))
+	out := []byte(lower)
+	for i, c := range out {
+		if c >= 'a' && c <= 'f' && hash[i] >= '8' {
+			out[i] = c - 'a' + 'A'
+		}
+	}
+	return "0x" + string(out)
+}
+
+const base58Alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+/*
+ParseAddress parses an Ethereum (0x...) or a Solana (base58) address.
+*/
+func ParseAddress(s string) (pb.Protocol, []byte, error) {
+	if strings.HasPrefix(s, "0x") {
+		address, err := hex.DecodeString(s[2:])
+		if err != nil || len(address) != 20 {
+			return 0, nil, fmt.Errorf("Invalid Ethereum address: %s", s)
+		}
+		return pb.Protocol_PROTOCOL_ETHEREUM, address, nil
+	}
+	address, err := base58Decode(s)
+	if err != nil || len(address) != 32 {
+		return 0, nil, fmt.Errorf("Invalid Solana address: %s", s)
+	}
+	return pb.Protocol_PROTOCOL_SOLANA, address, nil
+}
+
+func base58Encode(b []byte) string {
+	x := new(big.Int).SetBytes(b)
+	base := big.NewInt(58)
+	mod := new(big.Int)
+	out := []byte{}
+	for x.Sign() > 0 {
+		x.DivMod(x, base, mod)
+		out = append(out, base58Alphabet[mod.Int64()])
+	}
+	for _, c := range b {
+		if c != 0 {
+			break
+		}
+		out = append(out, base58Alphabet[0])
+	}
+	for i, j := 0, len(out)-1; i < j; i, j = i+1, j-1 {
+		out[i], out[j] = out[j], out[i]
+	}
+	return string(out)
+}
+
+func base58Decode(s string) ([]byte, error) {
+	x := new(big.Int)
+	base := big.NewInt(58)
+	for _, c := range s {
+		idx := strings.IndexRune(base58Alphabet, c)
+		if idx < 0 {
+			return nil, fmt.Errorf("Invalid base58 character %q", c)
+		}
+		x.Mul(x, base)
+		x.Add(x, big.NewInt(int64(idx)))
+	}
+	zeros := 0
+	for zeros < len(s) && s[zeros] == base58Alphabet[0] {
+		zeros++
+	}
+	return append(make([]byte, zeros), x.Bytes()...), nil
+}
@@ -0,0 +1,71 @@
+package fctools
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+
+	pb "github.com/vrypan/fargo/farcaster"
+)
+
+func Test_EthChecksumAddress(t *testing.T) {
+	// Test vector from EIP-55
+	address, _ := hex.DecodeString("5aaeb6053f3e94c9b9a09f33669435e7ef1beaed")
+	if s := EthChecksumAddress(address); s != "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed" {
+		t.Fatalf("Unexpected checksum address: %s", s)
+	}
+}
+
+func Test_Base58(t *testing.T) {
+	if s := base58Encode([]byte{0, 0, 1}); s != "112" {
+		t.Fatalf("Unexpected base58: %s", s)
+	}
+	if s := base58Encode([]byte("Hello World!")); s != "2NEpo7TZRRrLZSi2U" {
+		t.Fatalf("Unexpected base58: %s", s)
+	}
+	if b, err := base58Decode("112"); err != nil || !bytes.Equal(b, []byte{0, 0, 1}) {
+		t.Fatalf("Unexpected base58 decoding: %x %v", b, err)
+	}
+}
+
+func Test_VerificationJson(t *testing.T) {
+	address, _ := hex.DecodeString("5aaeb6053f3e94c9b9a09f33669435e7ef1beaed")
+	verifications := NewVerifications()
+	verifications.Messages = append(verifications.Messages, &Verification{Message: &pb.Message{
+		Data: &pb.MessageData{
+			Fid: 280,
+			Body: &pb.MessageData_VerificationAddAddressBody{VerificationAddAddressBody: &pb.VerificationAddAddressBody{
+				Address:  address,
+				Protocol: pb.Protocol_PROTOCOL_ETHEREUM,
+			}},
+		},
+	}})
+	if s := verifications.String(); s != "ethereum 0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed eoa\n" {
+		t.Fatalf("Unexpected string: %q", s)
+	}
+	if _, err := verifications.JsonList(true, false); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func Test_JsonOnChainEvent(t *testing.T) {
+	address, _ := hex.DecodeString("5aaeb6053f3e94c9b9a09f33669435e7ef1beaed")
+	event := &pb.OnChainEvent{
+		Fid:             280,
+		BlockHash:       []byte{0xab, 0xcd},
+		BlockTimestamp:  1700000000,
+		TransactionHash: []byte{0x01},
+		Body: &pb.OnChainEvent_IdRegisterEventBody{IdRegisterEventBody: &pb.IdRegisterEventBody{
+			To: address,
+		}},
+	}
+	s, err := JsonOnChainEvent(event, true, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{`"0xabcd"`, `"0x01"`, `"0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed"`, `"2023-11-14T`} {
+		if !bytes.Contains(s, []byte(expected)) {
+			t.Fatalf("%s not found in %s", expected, s)
+		}
+	}
+}
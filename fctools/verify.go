@@ -0,0 +1,63 @@
+package fctools
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"errors"
+
+	pb "github.com/vrypan/fargo/farcaster"
+	"github.com/zeebo/blake3"
+	"google.golang.org/protobuf/proto"
+)
+
+var (
+	ERR_UNSUPPORTED_SCHEME = errors.New("Unsupported hash or signature scheme")
+	ERR_HASH_MISMATCH      = errors.New("Hash does not match message data")
+	ERR_INVALID_SIGNATURE  = errors.New("Invalid signature")
+	ERR_DATA_MISMATCH      = errors.New("Message data does not match data_bytes")
+)
+
+/*
+VerifyMessage checks, without contacting a hub, that message.Hash is the
+BLAKE3 hash (first 20 bytes) of the message data and that
+message.Signature is a valid Ed25519 signature of the hash by message.Signer.
+
+If message.DataBytes is set, it is what the hash covers, so message.Data
+must decode from it: VerifyMessage fails with ERR_DATA_MISMATCH if they
+differ, and sets message.Data if it is missing.
+If message.DataBytes is empty, message.Data is serialized and hashed instead.
+VerifyMessage does not check that Signer is an active signer of the fid.
+*/
+func VerifyMessage(message *pb.Message) error {
+	if message.HashScheme != pb.HashScheme_HASH_SCHEME_BLAKE3 ||
+		message.SignatureScheme != pb.SignatureScheme_SIGNATURE_SCHEME_ED25519 {
+		return ERR_UNSUPPORTED_SCHEME
+	}
+	dataBytes := message.DataBytes
+	if len(dataBytes) > 0 {
+		data := &pb.MessageData{}
+		if err := proto.Unmarshal(dataBytes, data); err != nil {
+			return ERR_DATA_MISMATCH
+		}
+		if message.Data == nil {
+			message.Data = data
+		} else if !proto.Equal(data, message.Data) {
+			return ERR_DATA_MISMATCH
+		}
+	} else {
+		var err error
+		if dataBytes, err = proto.Marshal(message.Data); err != nil {
+			return err
+		}
+	}
+	hasher := blake3.New()
+	hasher.Write(dataBytes)
+	if hash := hasher.Sum(nil)[:20]; !bytes.Equal(hash, message.Hash) {
+		return ERR_HASH_MISMATCH
+	}
+	if len(message.Signer) != ed25519.PublicKeySize ||
+		!ed25519.Verify(ed25519.PublicKey(message.Signer), message.Hash, message.Signature) {
+		return ERR_INVALID_SIGNATURE
+	}
+	return nil
+}
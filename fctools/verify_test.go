@@ -0,0 +1,90 @@
+package fctools
+
+import (
+	"crypto/ed25519"
+	"testing"
+
+	pb "github.com/vrypan/fargo/farcaster"
+)
+
+func testMessage(t *testing.T) *pb.Message {
+	publicKey, privateKey, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	messageData := &pb.MessageData{
+		Type:      pb.MessageType_MESSAGE_TYPE_CAST_ADD,
+		Fid:       280,
+		Timestamp: 100000000,
+		Network:   pb.FarcasterNetwork_FARCASTER_NETWORK_MAINNET,
+		Body: &pb.MessageData_CastAddBody{
+			CastAddBody: &pb.CastAddBody{Text: "Hello"},
+		},
+	}
+	return CreateMessage(messageData, privateKey.Seed(), publicKey)
+}
+
+func Test_VerifyMessage(t *testing.T) {
+	message := testMessage(t)
+	if err := VerifyMessage(message); err != nil {
+		t.Fatalf("Expected a valid message, got %v", err)
+	}
+
+	message.DataBytes = nil
+	if err := VerifyMessage(message); err != nil {
+		t.Fatalf("Expected a valid message without data_bytes, got %v", err)
+	}
+
+	message.Data.GetCastAddBody().Text = "Tampered"
+	if err := VerifyMessage(message); err != ERR_HASH_MISMATCH {
+		t.Fatalf("Expected ERR_HASH_MISMATCH, got %v", err)
+	}
+
+	// data_bytes is kept, so the hash still matches, but data was changed.
+	message = testMessage(t)
+	message.Data.Fid = 3
+	message.Data.GetCastAddBody().Text = "Forged"
+	if err := VerifyMessage(message); err != ERR_DATA_MISMATCH {
+		t.Fatalf("Expected ERR_DATA_MISMATCH, got %v", err)
+	}
+
+	message = testMessage(t)
+	message.Data = nil
+	if err := VerifyMessage(message); err != nil || message.Data.GetFid() != 280 {
+		t.Fatalf("Expected data to be decoded from data_bytes, got %v", err)
+	}
+
+	message = testMessage(t)
+	message.Signature[0] ^= 0xff
+	if err := VerifyMessage(message); err != ERR_INVALID_SIGNATURE {
+		t.Fatalf("Expected ERR_INVALID_SIGNATURE, got %v", err)
+	}
+}
+
+func Test_UnmarshalMessages(t *testing.T) {
+	message := testMessage(t)
+	var all []byte
+	for _, opts := range []MarshalOptions{{}, {Bytes2Hash: true}, {Bytes2Hash: true, Timestamp2Date: true}} {
+		b, err := Marshal(message, opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		all = append(all, b...)
+		all = append(all, '\n')
+	}
+	messages, err := UnmarshalMessages(all)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(messages) != 3 {
+		t.Fatalf("Expected 3 messages, got %d", len(messages))
+	}
+	for i, m := range messages {
+		if err := VerifyMessage(m); err != nil {
+			t.Fatalf("Message %d: %v", i, err)
+		}
+		if m.Data.Timestamp != message.Data.Timestamp {
+			t.Fatalf("Message %d: timestamp %d, expected %d", i, m.Data.Timestamp, message.Data.Timestamp)
+		}
+	}
+}
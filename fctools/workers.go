@@ -0,0 +1,50 @@
+package fctools
+
+import (
+	"sync"
+)
+
+// Number of concurrent hub calls used to expand threads and look up fnames.
+const HUB_WORKERS = 8
+
+// parallel calls f(0)...f(n-1), running up to HUB_WORKERS calls at a time.
+func parallel(n int, f func(i int)) {
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < min(n, HUB_WORKERS); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				f(i)
+			}
+		}()
+	}
+	for i := 0; i < n; i++ {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+}
+
+/*
+collectFnames looks up the fname of every fid in fids that is not
+already in fnames. Each fid is looked up once, concurrently.
+*/
+func collectFnames(hub *FarcasterHub, fnames map[uint64]string, fids []uint64) {
+	missing := make([]uint64, 0, len(fids))
+	seen := make(map[uint64]bool, len(fids))
+	for _, fid := range fids {
+		if _, ok := fnames[fid]; !ok && !seen[fid] {
+			seen[fid] = true
+			missing = append(missing, fid)
+		}
+	}
+	found := make([]string, len(missing))
+	parallel(len(missing), func(i int) {
+		found[i], _ = hub.PrxGetUserDataStr(missing[i], "USER_DATA_TYPE_USERNAME")
+	})
+	for i, fid := range missing {
+		fnames[fid] = found[i]
+	}
+}
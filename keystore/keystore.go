@@ -0,0 +1,223 @@
+package keystore
+
+/*
+A passphrase-encrypted store of Ed25519 signer keys.
+
+Each key is stored in its own file, <config dir>/keys/<name>.json.
+The private key (the 32-byte Ed25519 seed) is encrypted with
+AES-256-GCM, using a key derived from the passphrase with scrypt.
+*/
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/vrypan/fargo/config"
+	"golang.org/x/crypto/scrypt"
+)
+
+var keys_path = ""
+
+const (
+	scryptN = 1 << 15
+	scryptR = 8
+	scryptP = 1
+)
+
+var (
+	ERR_NOT_FOUND      = errors.New("Key not found")
+	ERR_EXISTS         = errors.New("Key already exists")
+	ERR_BAD_PASSPHRASE = errors.New("Wrong passphrase")
+	ERR_BAD_NAME       = errors.New("Key names may only contain a-z, 0-9, '.', '_' and '-'")
+)
+
+var nameRe = regexp.MustCompile(`^[a-z0-9._-]+$`)
+
+func init() {
+	if keys_path == "" {
+		configDir, err := config.ConfigDir()
+		if err != nil {
+			panic(err)
+		}
+		keys_path = filepath.Join(configDir, "keys")
+	}
+}
+
+func Path() string {
+	return keys_path
+}
+
+// SetPath changes the keystore directory. Used by tests.
+func SetPath(p string) {
+	keys_path = p
+}
+
+type Key struct {
+	Name       string    `json:"name"`
+	PublicKey  string    `json:"public_key"`
+	CreatedAt  time.Time `json:"created_at"`
+	Salt       string    `json:"salt"`
+	Nonce      string    `json:"nonce"`
+	Ciphertext string    `json:"ciphertext"`
+	N          int       `json:"scrypt_n"`
+	R          int       `json:"scrypt_r"`
+	P          int       `json:"scrypt_p"`
+}
+
+func keyFile(name string) string {
+	return filepath.Join(keys_path, name+".json")
+}
+
+// Generate creates a new random keypair and stores it as name.
+func Generate(name string, passphrase string) (*Key, error) {
+	_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	return Import(name, privateKey.Seed(), passphrase)
+}
+
+/*
+Import stores an existing private key as name. privateKey can be
+the 32-byte seed (the format of cast.privkey) or the 64-byte
+seed+public key.
+*/
+func Import(name string, privateKey []byte, passphrase string) (*Key, error) {
+	if !nameRe.MatchString(name) {
+		return nil, ERR_BAD_NAME
+	}
+	if _, err := os.Stat(keyFile(name)); err == nil {
+		return nil, ERR_EXISTS
+	}
+	if len(privateKey) == ed25519.PrivateKeySize {
+		privateKey = privateKey[:ed25519.SeedSize]
+	}
+	if len(privateKey) != ed25519.SeedSize {
+		return nil, fmt.Errorf("Private key must be %d bytes, got %d", ed25519.SeedSize, len(privateKey))
+	}
+	publicKey := ed25519.NewKeyFromSeed(privateKey).Public().(ed25519.PublicKey)
+
+	salt := make([]byte, 32)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	key := &Key{
+		Name:      name,
+		PublicKey: "0x" + hex.EncodeToString(publicKey),
+		CreatedAt: time.Now().UTC().Truncate(time.Second),
+		Salt:      hex.EncodeToString(salt),
+		N:         scryptN,
+		R:         scryptR,
+		P:         scryptP,
+	}
+	aead, err := key.aead(passphrase)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	key.Nonce = hex.EncodeToString(nonce)
+	key.Ciphertext = hex.EncodeToString(aead.Seal(nil, nonce, privateKey, []byte(key.PublicKey)))
+
+	if err := os.MkdirAll(keys_path, 0700); err != nil {
+		return nil, err
+	}
+	b, err := json.MarshalIndent(key, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(keyFile(name), b, 0600); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// Load reads the (still encrypted) key name.
+func Load(name string) (*Key, error) {
+	b, err := os.ReadFile(keyFile(name))
+	if os.IsNotExist(err) {
+		return nil, ERR_NOT_FOUND
+	}
+	if err != nil {
+		return nil, err
+	}
+	var key Key
+	if err := json.Unmarshal(b, &key); err != nil {
+		return nil, fmt.Errorf("%s: %w", keyFile(name), err)
+	}
+	return &key, nil
+}
+
+// List returns all the keys in the keystore, sorted by name.
+func List() ([]*Key, error) {
+	files, err := filepath.Glob(filepath.Join(keys_path, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+	keys := make([]*Key, 0, len(files))
+	for _, f := range files {
+		key, err := Load(strings.TrimSuffix(filepath.Base(f), ".json"))
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+func (key *Key) aead(passphrase string) (cipher.AEAD, error) {
+	salt, err := hex.DecodeString(key.Salt)
+	if err != nil {
+		return nil, err
+	}
+	derived, err := scrypt.Key([]byte(passphrase), salt, key.N, key.R, key.P, 32)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(derived)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func (key *Key) PublicKeyBytes() []byte {
+	b, _ := hex.DecodeString(strings.TrimPrefix(key.PublicKey, "0x"))
+	return b
+}
+
+// Decrypt returns the private key (32-byte seed) and the public key.
+func (key *Key) Decrypt(passphrase string) ([]byte, []byte, error) {
+	aead, err := key.aead(passphrase)
+	if err != nil {
+		return nil, nil, err
+	}
+	nonce, err := hex.DecodeString(key.Nonce)
+	if err != nil {
+		return nil, nil, err
+	}
+	ciphertext, err := hex.DecodeString(key.Ciphertext)
+	if err != nil {
+		return nil, nil, err
+	}
+	privateKey, err := aead.Open(nil, nonce, ciphertext, []byte(key.PublicKey))
+	if err != nil {
+		return nil, nil, ERR_BAD_PASSPHRASE
+	}
+	return privateKey, key.PublicKeyBytes(), nil
+}
@@ -0,0 +1,64 @@
+package keystore
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"testing"
+)
+
+func TestGenerateDecrypt(t *testing.T) {
+	SetPath(t.TempDir())
+
+	key, err := Generate("test", "secret")
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	if _, err := Generate("test", "secret"); err != ERR_EXISTS {
+		t.Fatalf("Expected ERR_EXISTS, got %v", err)
+	}
+
+	loaded, err := Load("test")
+	if err != nil {
+		t.Fatalf("Failed to load key: %v", err)
+	}
+	if _, _, err := loaded.Decrypt("wrong"); err != ERR_BAD_PASSPHRASE {
+		t.Fatalf("Expected ERR_BAD_PASSPHRASE, got %v", err)
+	}
+	privateKey, publicKey, err := loaded.Decrypt("secret")
+	if err != nil {
+		t.Fatalf("Failed to decrypt key: %v", err)
+	}
+	derived := ed25519.NewKeyFromSeed(privateKey).Public().(ed25519.PublicKey)
+	if !bytes.Equal(derived, publicKey) || loaded.PublicKey != key.PublicKey {
+		t.Fatal("Public key does not match private key")
+	}
+}
+
+func TestImportList(t *testing.T) {
+	SetPath(t.TempDir())
+
+	seed := bytes.Repeat([]byte{1}, ed25519.SeedSize)
+	if _, err := Import("b", seed, "pass"); err != nil {
+		t.Fatalf("Failed to import key: %v", err)
+	}
+	if _, err := Import("a", ed25519.NewKeyFromSeed(seed), "pass"); err != nil {
+		t.Fatalf("Failed to import 64-byte key: %v", err)
+	}
+	if _, err := Import("Bad Name", seed, "pass"); err != ERR_BAD_NAME {
+		t.Fatalf("Expected ERR_BAD_NAME, got %v", err)
+	}
+	if _, err := Load("missing"); err != ERR_NOT_FOUND {
+		t.Fatalf("Expected ERR_NOT_FOUND, got %v", err)
+	}
+
+	keys, err := List()
+	if err != nil {
+		t.Fatalf("Failed to list keys: %v", err)
+	}
+	if len(keys) != 2 || keys[0].Name != "a" || keys[1].Name != "b" {
+		t.Fatalf("Unexpected key list: %v", keys)
+	}
+	if keys[0].PublicKey != keys[1].PublicKey {
+		t.Fatal("Same seed should give the same public key")
+	}
+}
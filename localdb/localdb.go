@@ -64,6 +64,13 @@ func Set(k string, v []byte) error {
 	return err
 }
 
+// SetPermanent is like Set, but the entry never expires.
+func SetPermanent(k string, v []byte) error {
+	return db.Update(func(txn *badger.Txn) error {
+		return txn.Set([]byte(k), v)
+	})
+}
+
 func Get(k string) ([]byte, error) {
 	var val []byte
 	err := db.View(func(txn *badger.Txn) error {
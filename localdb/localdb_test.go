@@ -30,3 +30,18 @@ func TestBasic(t *testing.T) {
 		t.Errorf("Expected value '%v', got '%v'", value, retrievedValue)
 	}
 }
+
+func TestSetPermanent(t *testing.T) {
+	err := Open()
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	defer Close()
+
+	if err = SetPermanent("testPermanentKey", []byte("1")); err != nil {
+		t.Fatalf("Failed to store data: %v", err)
+	}
+	if v, err := Get("testPermanentKey"); err != nil || string(v) != "1" {
+		t.Errorf("Expected value '1', got '%s' (%v)", v, err)
+	}
+}
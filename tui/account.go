@@ -0,0 +1,52 @@
+package tui
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/go-color-term/go-color-term/coloring"
+	"github.com/vrypan/fargo/fctools"
+)
+
+/*
+PpAccount prints the on-chain summary of an account.
+fnames are used to show the names of the apps that added each signer.
+*/
+func PpAccount(account *fctools.Account, fnames map[uint64]string) string {
+	var builder strings.Builder
+	builder.WriteString(coloring.Bold("Fid") + "       " + strconv.FormatUint(account.Fid, 10) + "\n")
+	builder.WriteString(coloring.Bold("Custody") + "   " + account.CustodyAddress + "\n")
+	builder.WriteString(coloring.Bold("Recovery") + "  " + account.RecoveryAddress + "\n")
+	builder.WriteString(coloring.Bold("Registered") + " block " + strconv.FormatUint(uint64(account.RegisteredBlock), 10))
+	builder.WriteString(coloring.Faint(" ("+account.RegisteredAt.Format("2006-01-02 15:04")+")") + "\n")
+
+	builder.WriteString("\n" + coloring.Bold("Signers") + "\n")
+	for _, s := range account.Signers {
+		builder.WriteString(coloring.Faint("[" + s.AddedAt.Format("2006-01-02 15:04") + "] "))
+		builder.WriteString(s.Key + " " + s.KeyType)
+		if s.AppFid != 0 {
+			builder.WriteString(" app: " + PpFname(fnames[s.AppFid]))
+			builder.WriteString(coloring.Faint(" (" + strconv.FormatUint(s.AppFid, 10) + ")"))
+		}
+		builder.WriteString("\n")
+	}
+
+	builder.WriteString("\n" + coloring.Bold("Storage") + coloring.Faint(" ("+strconv.FormatUint(uint64(account.StorageUnits), 10)+" units)") + "\n")
+	builder.WriteString(PpStorage(account.Storage))
+	return builder.String()
+}
+
+// PpStorage prints a table of used/available messages per store.
+func PpStorage(storage []fctools.StoreUsage) string {
+	var builder strings.Builder
+	builder.WriteString(coloring.Faint(fmt.Sprintf("%-16s %8s %8s %9s\n", "store", "used", "limit", "available")))
+	for _, s := range storage {
+		line := fmt.Sprintf("%-16s %8d %8d %9d", s.Store, s.Used, s.Limit, s.Available())
+		if s.Available() == 0 {
+			line = coloring.Red(line)
+		}
+		builder.WriteString(line + "\n")
+	}
+	return builder.String()
+}
@@ -0,0 +1,30 @@
+package tui
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/go-color-term/go-color-term/coloring"
+	"github.com/vrypan/fargo/fctools"
+)
+
+/*
+PpLinksList prints one line per link.
+If inbound is true, the user that created the link is shown (ex. followers),
+otherwise the link target is shown (ex. following).
+*/
+func PpLinksList(links *fctools.Links, inbound bool) string {
+	var builder strings.Builder
+	for _, l := range links.Messages {
+		fid := l.TargetFid()
+		if inbound {
+			fid = l.Fid()
+		}
+		builder.WriteString(ppTimestamp(l.Message.Data.Timestamp))
+		builder.WriteString(" ")
+		builder.WriteString(PpFname(links.Fnames[fid]))
+		builder.WriteString(coloring.Faint(" (" + strconv.FormatUint(fid, 10) + ")"))
+		builder.WriteString("\n")
+	}
+	return builder.String()
+}
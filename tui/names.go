@@ -0,0 +1,34 @@
+package tui
+
+import (
+	"encoding/hex"
+	"strings"
+
+	"github.com/go-color-term/go-color-term/coloring"
+	"github.com/vrypan/fargo/fctools"
+)
+
+/*
+PpUsernameProofs prints one line per username proof. If the proofs
+have been verified, mismatched owners are highlighted.
+*/
+func PpUsernameProofs(proofs *fctools.UsernameProofs) string {
+	var builder strings.Builder
+	for _, p := range proofs.Proofs {
+		builder.WriteString(coloring.Faint("[" + p.Time().Format("2006-01-02 15:04") + "] "))
+		builder.WriteString(coloring.Magenta(p.Name()))
+		builder.WriteString(coloring.Faint(" " + p.Type() + " owner: "))
+		builder.WriteString(p.Owner())
+		switch p.OwnerMatch {
+		case "":
+		case fctools.OWNER_MATCH_NONE:
+			builder.WriteString(" " + coloring.Red("owner is not the custody or a verified address"))
+		default:
+			builder.WriteString(" " + coloring.Green("ok ("+p.OwnerMatch+")"))
+		}
+		builder.WriteString("\n")
+		builder.WriteString(coloring.Faint("  signature: 0x" + hex.EncodeToString(p.Proof.Signature)))
+		builder.WriteString("\n")
+	}
+	return builder.String()
+}
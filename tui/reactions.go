@@ -1,8 +1,10 @@
 package tui
 
 import (
+	"strconv"
 	"strings"
 
+	"github.com/go-color-term/go-color-term/coloring"
 	"github.com/vrypan/fargo/fctools"
 )
 
@@ -31,3 +33,25 @@ func PpReactionsList(reactions *fctools.Reactions, casts *fctools.CastGroup, opt
 	}
 	return builder.String()
 }
+
+/*
+PpReactorsList lists the users that reacted to a cast,
+one per line, after total, the number of reactions of the cast.
+*/
+func PpReactorsList(reactions *fctools.Reactions, verb string, total int) string {
+	var builder strings.Builder
+	builder.WriteString(strconv.Itoa(total) + " " + verb)
+	if len(reactions.Messages) < total {
+		builder.WriteString(coloring.Faint(" (showing " + strconv.Itoa(len(reactions.Messages)) + ")"))
+	}
+	builder.WriteString("\n")
+	for _, r := range reactions.Messages {
+		fid := r.Message.Data.Fid
+		builder.WriteString(ppTimestamp(r.Message.Data.Timestamp))
+		builder.WriteString(" ")
+		builder.WriteString(PpFname(reactions.Fnames[fid]))
+		builder.WriteString(coloring.Faint(" (" + strconv.FormatUint(fid, 10) + ")"))
+		builder.WriteString("\n")
+	}
+	return builder.String()
+}
@@ -60,6 +60,23 @@ func boldBlock(s string) string {
 }
 
 func FormatCast(msg *pb.Message, fnames map[uint64]string, padding int, showInReply bool, highlight string, grep string) string {
+	return formatCast(msg, fnames, padding, showInReply, highlight, grep, "")
+}
+
+// ppStats formats CastStats as one line. Returns "" if stats is nil.
+func ppStats(stats *fctools.CastStats) string {
+	if stats == nil {
+		return ""
+	}
+	return coloring.Faint(
+		strconv.Itoa(stats.Likes) + " likes · " +
+			strconv.Itoa(stats.Recasts) + " recasts · " +
+			strconv.Itoa(stats.Replies) + " replies",
+	)
+}
+
+// formatCast is FormatCast with an optional footer line, shown at the bottom of the cast.
+func formatCast(msg *pb.Message, fnames map[uint64]string, padding int, showInReply bool, highlight string, grep string, footer string) string {
 
 	body := pb.CastAddBody(*msg.Data.GetCastAddBody())
 
@@ -114,6 +131,9 @@ func FormatCast(msg *pb.Message, fnames map[uint64]string, padding int, showInRe
 			builder.WriteString(ppUrl(embed.GetUrl()))
 		}
 	}
+	if footer != "" {
+		builder.WriteString("\n\n" + footer)
+	}
 	out := builder.String()
 
 	builder.Reset()
@@ -151,12 +171,14 @@ func PprintThread(grp *fctools.CastGroup, hash *fctools.Hash, padding int, hilig
 	}
 	out := ""
 	var cast *pb.Message
+	var stats *fctools.CastStats
 	if msg, ok := grp.Messages[*hash]; ok {
 		cast = msg.Message
+		stats = msg.Stats
 	} else {
 		return ""
 	}
-	out += FormatCast(cast, grp.Fnames, padding, (padding == 0), hilightHash, grep)
+	out += formatCast(cast, grp.Fnames, padding, (padding == 0), hilightHash, grep, ppStats(stats))
 	for _, reply := range grp.Messages[*hash].Replies {
 		out += PprintThread(grp, &reply, padding+4, hilightHash, grep)
 	}
@@ -164,14 +186,8 @@ func PprintThread(grp *fctools.CastGroup, hash *fctools.Hash, padding int, hilig
 }
 func PprintCastList(grp *fctools.CastGroup, hash *fctools.Hash, padding int, grep string) string {
 	out := strings.Builder{}
-	if len(grp.Ordered) > 0 {
-		for _, h := range grp.Ordered {
-			out.WriteString(FmtCast(grp.Messages[h].Message, grp.Fnames, padding, true, &FmtCastOpts{Grep: grep, Highlight: "", Width: 50}))
-		}
-	} else {
-		for _, cast := range grp.Messages {
-			out.WriteString(FmtCast(cast.Message, grp.Fnames, padding, true, &FmtCastOpts{Grep: grep, Highlight: "", Width: 50}))
-		}
+	for _, h := range grp.Hashes() {
+		out.WriteString(FmtCast(grp.Messages[h].Message, grp.Fnames, padding, true, &FmtCastOpts{Grep: grep, Highlight: "", Width: 50}))
 	}
 	return out.String()
 }
@@ -0,0 +1,21 @@
+package tui
+
+import (
+	"strings"
+
+	"github.com/go-color-term/go-color-term/coloring"
+	"github.com/vrypan/fargo/fctools"
+)
+
+// PpVerificationsList prints one line per verified address.
+func PpVerificationsList(verifications *fctools.Verifications) string {
+	var builder strings.Builder
+	for _, v := range verifications.Messages {
+		builder.WriteString(ppTimestamp(v.Message.Data.Timestamp))
+		builder.WriteString(" ")
+		builder.WriteString(v.Address())
+		builder.WriteString(coloring.Faint(" " + v.Protocol() + " " + v.Type()))
+		builder.WriteString("\n")
+	}
+	return builder.String()
+}
@@ -48,7 +48,11 @@ type CastsModel struct {
 	activeField int
 
 	resultsNum uint32
-	statusBar  *StatusBar
+	sortMode   fctools.SortMode
+	// Stats of the casts seen so far, so that going back to a view
+	// does not fetch them again.
+	stats     map[fctools.Hash]*fctools.CastStats
+	statusBar *StatusBar
 }
 
 type View struct {
@@ -59,8 +63,8 @@ type View struct {
 }
 
 func NewCastsModel() *CastsModel {
-	m := CastsModel{}
-	statusText := "↑/↓/←/→ navigate • q quit"
+	m := CastsModel{stats: make(map[fctools.Hash]*fctools.CastStats)}
+	statusText := "↑/↓/←/→ navigate • l like • r recast • q quit"
 	m.statusBar = NewStatusBar().SetText(statusText).SetHeight(1)
 	return &m
 }
@@ -69,21 +73,39 @@ func (m *CastsModel) SetResultsCount(count uint32) {
 	m.resultsNum = count
 }
 
+func (m *CastsModel) SetSortMode(mode fctools.SortMode) {
+	m.sortMode = mode
+}
+
 func (m *CastsModel) LoadCasts(fid uint64, hash []byte) *CastsModel {
 	m.view = VIEW_THREAD
-	m.prepareModel(fctools.NewCastGroup().FromCast(nil, &farcaster.CastId{Fid: fid, Hash: hash}, true))
+	hub := fctools.NewFarcasterHub()
+	defer hub.Close()
+	m.prepareModel(hub, fctools.NewCastGroup().FromCast(hub, &farcaster.CastId{Fid: fid, Hash: hash}, true))
 	return m
 }
 
 func (m *CastsModel) LoadFid(fid uint64) *CastsModel {
 	m.view = VIEW_LIST
-	m.prepareModel(fctools.NewCastGroup().FromFid(nil, fid, m.resultsNum))
+	hub := fctools.NewFarcasterHub()
+	defer hub.Close()
+	m.prepareModel(hub, fctools.NewCastGroup().FromFid(hub, fid, m.resultsNum))
 	return m
 }
 
-func (m *CastsModel) prepareModel(casts *fctools.CastGroup) {
+func (m *CastsModel) prepareModel(hub *fctools.FarcasterHub, casts *fctools.CastGroup) {
 	m.focus = false
 	m.activeField = 0
+	// SortBy only fetches the stats of casts not seen before.
+	for hash, cast := range casts.Messages {
+		cast.Stats = m.stats[hash]
+	}
+	casts.SortBy(hub, m.sortMode)
+	for hash, cast := range casts.Messages {
+		if cast.Stats != nil {
+			m.stats[hash] = cast.Stats
+		}
+	}
 	m.casts = *casts
 	m.blocks = make([]castsBlock, len(casts.Messages))
 	m.hashIdx = make([]fctools.Hash, len(casts.Messages))